@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
+	"path/filepath"
 	"sort"
 	"strconv"
 
@@ -32,13 +33,35 @@ const (
 	SurveyExit        = "exit"
 )
 
+// resourceNvidiaGPU is the standard device-plugin resource name for a whole,
+// exclusively-held GPU, as opposed to the shared-memory resource tracked by
+// gpushareutils.ResourceName.
+const resourceNvidiaGPU corev1.ResourceName = "nvidia.com/gpu"
+
 type Options struct {
 	SimonConfig                string
 	DefaultSchedulerConfigFile string
-	UseGreed                   bool
 	Interactive                bool
 	ExtendedResources          []string
 	OutputFile                 string
+	// OutputFormat selects how Run reports its result: "table" (default,
+	// pterm tables and interactive survey prompts), "json" or "yaml" (a
+	// single SimulationReport, interactive prompts disabled).
+	OutputFormat string
+	// AutoScale picks node template(s) and counts automatically instead of
+	// driving the interactive add-node survey; see autoScaleNodeCounts.
+	AutoScale bool
+	// ScoringPolicy selects how the scheduler ranks candidate nodes: one of
+	// simulator.ScoringPolicyBinpack, ScoringPolicySpread,
+	// ScoringPolicyLeastAllocated or ScoringPolicyMostAllocated. Replaces the
+	// old UseGreed boolean, which only ever meant binpack-vs-spread.
+	ScoringPolicy string
+	// GPUScoringPolicy is passed to simulator.WithGPUScoringPolicy as the
+	// device-selection policy among the GPUs on a chosen node, independently
+	// of ScoringPolicy. Defaults to ScoringPolicy when empty. The simulator
+	// records it but no scheduler plugin reads it, so it does not change
+	// which GPU a pod is placed on.
+	GPUScoringPolicy string
 }
 
 type Applier struct {
@@ -46,10 +69,13 @@ type Applier struct {
 	appList           []v1alpha1.AppInfo
 	newNodePath       string
 	schedulerConfig   string
-	useGreed          bool
 	interactive       bool
 	extendedResources []string
 	outputFile        *os.File
+	outputFormat      string
+	autoScale         bool
+	scoringPolicy     string
+	gpuScoringPolicy  string
 }
 
 type Interface interface {
@@ -80,15 +106,32 @@ func NewApplier(opts Options) Interface {
 		}
 	}
 
+	outputFormat := opts.OutputFormat
+	if outputFormat == "" {
+		outputFormat = OutputFormatTable
+	}
+
+	scoringPolicy := opts.ScoringPolicy
+	if scoringPolicy == "" {
+		scoringPolicy = simulator.ScoringPolicyLeastAllocated
+	}
+	gpuScoringPolicy := opts.GPUScoringPolicy
+	if gpuScoringPolicy == "" {
+		gpuScoringPolicy = scoringPolicy
+	}
+
 	applier := &Applier{
 		cluster:           simonCR.Spec.Cluster,
 		appList:           simonCR.Spec.AppList,
 		newNodePath:       simonCR.Spec.NewNode,
 		schedulerConfig:   opts.DefaultSchedulerConfigFile,
-		useGreed:          opts.UseGreed,
 		interactive:       opts.Interactive,
 		extendedResources: opts.ExtendedResources,
 		outputFile:        outputFile,
+		outputFormat:      outputFormat,
+		autoScale:         opts.AutoScale,
+		scoringPolicy:     scoringPolicy,
+		gpuScoringPolicy:  gpuScoringPolicy,
 	}
 
 	if err := validate(applier); err != nil {
@@ -150,26 +193,21 @@ func (applier *Applier) Run() (err error) {
 		resourceList = append(resourceList, app.Name)
 	}
 
-	// Step 3: convert the path of the new node to be added into the kubernetes object
-	// only support temporarily one type of node at present
-	var nodeResource simulator.ResourceTypes
-	var newNode *corev1.Node
+	// Step 3: convert the path(s) of the new node template(s) to be added into kubernetes objects.
+	// applier.newNodePath may point either directly at a template (a single node type) or at a
+	// directory of templates (one sub-directory per node type), so several heterogeneous pools
+	// can be scaled independently by the add-node loop below.
+	var nodeTemplates []NodeTemplate
 	if applier.newNodePath != "" {
-		if content, err = utils.GetYamlContentFromDirectory(applier.newNodePath); err != nil {
-			return err
-		}
-		if nodeResource, err = simulator.GetObjectFromYamlContent(content); err != nil {
+		if nodeTemplates, err = loadNodeTemplates(applier.newNodePath); err != nil {
 			return err
 		}
-		simulator.MatchAndSetLocalStorageAnnotationOnNode(nodeResource.Nodes, applier.newNodePath)
-		// only support temporarily adding a type of node at present
-		newNode = nodeResource.Nodes[0]
 	}
 
 	// confirm the list of applications that needed to be deployed in interactive mode
 	var selectedAppNameList []string
 	var selectedResourceList []simulator.AppResource
-	if len(resourceList) != 0 && applier.interactive {
+	if len(resourceList) != 0 && applier.interactive && applier.outputFormat == OutputFormatTable {
 		var multiQs = []*survey.Question{
 			{
 				Name: "APPs",
@@ -194,29 +232,52 @@ func (applier *Applier) Run() (err error) {
 	}
 
 	// Step 4: determining that the current cluster can deploy selected applications and meets the given requests,
-	// If everything is ok, output the result. Otherwise we adjust the scale of cluster by adding node
+	// If everything is ok, output the result. Otherwise we adjust the scale of cluster by adding node(s)
+	simOpts := simulateOptions{
+		disablePTerm:     disablePTerm,
+		kubeconfig:       applier.cluster.KubeConfig,
+		scoringPolicy:    applier.scoringPolicy,
+		gpuScoringPolicy: applier.gpuScoringPolicy,
+	}
 	var result *simulator.SimulateResult
 	canBeScheduled := false
-	newNodeNum := 0
+	newNodeCounts := make(map[string]int)
+	if applier.autoScale && len(nodeTemplates) > 0 {
+		var err error
+		newNodeCounts, err = autoScaleNodeCounts(clusterResourceCopy, selectedResourceList, nodeTemplates, simOpts)
+		if err != nil {
+			return err
+		}
+	}
+	oneShot := applier.outputFormat != OutputFormatTable || applier.autoScale
 	choose := ""
 	for {
 		if choose != SurveyShowResults {
-			newClusterResource := clusterResourceCopy
-			nodes, err := utils.NewFakeNodes(newNode, newNodeNum)
+			result, err = simulateWithNodeCounts(clusterResourceCopy, selectedResourceList, nodeTemplates, newNodeCounts, simOpts)
 			if err != nil {
 				return err
 			}
-			newClusterResource.Nodes = append(newClusterResource.Nodes, nodes...)
-			result, err = simulator.Simulate(newClusterResource, selectedResourceList, simulator.DisablePTerm(disablePTerm), simulator.WithKubeConfig(applier.cluster.KubeConfig))
+		}
 
-			if err != nil {
-				return err
+		if oneShot {
+			// Scripted runs (non-table output, or --auto-scale already having
+			// picked a node mix) drive a single pass instead of the
+			// interactive add-node survey.
+			if len(result.UnscheduledPods) == 0 {
+				if ok, reason, err := satisfyResourceSetting(result.NodeStatus); err != nil {
+					return err
+				} else if ok {
+					canBeScheduled = true
+				} else {
+					pterm.FgLightWhite.Println(reason)
+				}
 			}
+			break
 		}
 
 		if len(result.UnscheduledPods) != 0 {
 			prompt := &survey.Select{
-				Message: fmt.Sprintf("there are still %d pod(s) that can not be scheduled when add %d nodes, you can:", len(result.UnscheduledPods), newNodeNum),
+				Message: fmt.Sprintf("there are still %d pod(s) that can not be scheduled when add %d node(s), you can:", len(result.UnscheduledPods), totalNodeCount(newNodeCounts)),
 				Options: []string{SurveyShowResults, SurveyAddNode, SurveyExit},
 			}
 			err = survey.AskOne(prompt, &choose)
@@ -230,15 +291,9 @@ func (applier *Applier) Run() (err error) {
 					pterm.FgLightWhite.Printf("%4d %s/%s: %s\n", i, pod.Pod.Namespace, pod.Pod.Name, pod.Reason)
 				}
 			case SurveyAddNode:
-				num := 0
-				prompt := &survey.Input{
-					Message: "input node number",
-				}
-				err = survey.AskOne(prompt, &num)
-				if err != nil {
+				if err := askNodeCounts(nodeTemplates, newNodeCounts); err != nil {
 					log.Fatalf("%v", err)
 				}
-				newNodeNum = num
 			case SurveyExit:
 				exitSimulation = true
 			}
@@ -257,6 +312,10 @@ func (applier *Applier) Run() (err error) {
 		}
 	}
 
+	if applier.outputFormat != OutputFormatTable {
+		return applier.writeReport(buildSimulationReport(result, applier.extendedResources, selectedAppNameList, canBeScheduled, totalNodeCount(newNodeCounts)))
+	}
+
 	if canBeScheduled {
 		pterm.FgGreen.Println("Simulation success!")
 		report(result.NodeStatus, applier.extendedResources, selectedAppNameList)
@@ -304,6 +363,216 @@ func validate(applier *Applier) error {
 	return nil
 }
 
+// labelNewNodeTemplate records, on a node generated by the add-node loop,
+// which template it was scaled from, so the report can group the "New Node"
+// column by template name instead of a single checkmark.
+const labelNewNodeTemplate = "simon/new-node-template"
+
+// NodeTemplate is one candidate node type that the add-node loop can scale.
+type NodeTemplate struct {
+	Name string
+	Node *corev1.Node
+}
+
+// loadNodeTemplates reads one or more node templates from path. When path
+// contains sub-directories, each sub-directory is treated as a distinct
+// template named after the directory, so heterogeneous node pools can be
+// scaled independently. Otherwise the whole path is treated as a single
+// template named "default", preserving the previous single-template behavior.
+func loadNodeTemplates(path string) ([]NodeTemplate, error) {
+	entries, err := ioutil.ReadDir(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read new node path(%s): %v ", path, err)
+	}
+
+	var subdirs []os.FileInfo
+	for _, entry := range entries {
+		if entry.IsDir() {
+			subdirs = append(subdirs, entry)
+		}
+	}
+
+	if len(subdirs) == 0 {
+		node, err := loadSingleNodeTemplate(path)
+		if err != nil {
+			return nil, err
+		}
+		return []NodeTemplate{{Name: "default", Node: node}}, nil
+	}
+
+	var templates []NodeTemplate
+	for _, dir := range subdirs {
+		node, err := loadSingleNodeTemplate(filepath.Join(path, dir.Name()))
+		if err != nil {
+			return nil, err
+		}
+		templates = append(templates, NodeTemplate{Name: dir.Name(), Node: node})
+	}
+	return templates, nil
+}
+
+func loadSingleNodeTemplate(path string) (*corev1.Node, error) {
+	content, err := utils.GetYamlContentFromDirectory(path)
+	if err != nil {
+		return nil, err
+	}
+	nodeResource, err := simulator.GetObjectFromYamlContent(content)
+	if err != nil {
+		return nil, err
+	}
+	if len(nodeResource.Nodes) == 0 {
+		return nil, fmt.Errorf("no node object found under %s", path)
+	}
+	simulator.MatchAndSetLocalStorageAnnotationOnNode(nodeResource.Nodes, path)
+	return nodeResource.Nodes[0], nil
+}
+
+// simulateOptions carries the knobs of Applier that every simulateWithNodeCounts
+// call needs, so adding one doesn't grow that function's parameter list.
+type simulateOptions struct {
+	disablePTerm     bool
+	kubeconfig       string
+	scoringPolicy    string
+	gpuScoringPolicy string
+}
+
+// simulateWithNodeCounts runs one simulation with counts[name] fake nodes
+// added for each template in templates, on top of cluster.
+func simulateWithNodeCounts(cluster simulator.ResourceTypes, apps []simulator.AppResource, templates []NodeTemplate, counts map[string]int, opts simulateOptions) (*simulator.SimulateResult, error) {
+	newCluster := cluster
+	for _, tmpl := range templates {
+		count := counts[tmpl.Name]
+		if count <= 0 {
+			continue
+		}
+		nodes, err := utils.NewFakeNodes(tmpl.Node, count)
+		if err != nil {
+			return nil, err
+		}
+		for _, node := range nodes {
+			if node.Labels == nil {
+				node.Labels = make(map[string]string)
+			}
+			node.Labels[labelNewNodeTemplate] = tmpl.Name
+		}
+		newCluster.Nodes = append(newCluster.Nodes, nodes...)
+	}
+	return simulator.Simulate(newCluster, apps,
+		simulator.DisablePTerm(opts.disablePTerm),
+		simulator.WithKubeConfig(opts.kubeconfig),
+		simulator.WithScoringPolicy(opts.scoringPolicy),
+		simulator.WithGPUScoringPolicy(opts.gpuScoringPolicy),
+	)
+}
+
+// autoScaleNodeCounts greedily grows a node-count map until either every pod
+// schedules or no template makes further progress. At each step it picks the
+// template that schedules the most additional pods per unit of CPU+memory
+// added (a first-fit-decreasing heuristic against the remaining
+// UnscheduledPods), mirroring --auto-scale's non-interactive contract.
+func autoScaleNodeCounts(cluster simulator.ResourceTypes, apps []simulator.AppResource, templates []NodeTemplate, opts simulateOptions) (map[string]int, error) {
+	counts := make(map[string]int)
+	for {
+		result, err := simulateWithNodeCounts(cluster, apps, templates, counts, opts)
+		if err != nil {
+			return nil, err
+		}
+		if len(result.UnscheduledPods) == 0 {
+			return counts, nil
+		}
+
+		reductions := make(map[string]int, len(templates))
+		for _, tmpl := range templates {
+			trial := make(map[string]int, len(counts))
+			for name, count := range counts {
+				trial[name] = count
+			}
+			trial[tmpl.Name]++
+
+			trialResult, err := simulateWithNodeCounts(cluster, apps, templates, trial, opts)
+			if err != nil {
+				return nil, err
+			}
+			reductions[tmpl.Name] = len(result.UnscheduledPods) - len(trialResult.UnscheduledPods)
+		}
+
+		bestTemplate := bestScalingTemplate(templates, reductions)
+		if bestTemplate == "" {
+			// no template reduces the unscheduled count any further
+			return counts, nil
+		}
+		counts[bestTemplate]++
+	}
+}
+
+// bestScalingTemplate picks the template whose next increment reduces the
+// unscheduled pod count the most per unit of CPU+memory it would add,
+// mirroring a first-fit-decreasing heuristic. reductions maps a template
+// name to how many fewer pods went unscheduled in a trial with one more
+// node of that template. It returns "" when no template has a positive
+// reduction.
+func bestScalingTemplate(templates []NodeTemplate, reductions map[string]int) string {
+	bestTemplate := ""
+	bestScore := 0.0
+	for _, tmpl := range templates {
+		reduction := reductions[tmpl.Name]
+		if reduction <= 0 {
+			continue
+		}
+		cost := float64(tmpl.Node.Status.Allocatable.Cpu().MilliValue()) + float64(tmpl.Node.Status.Allocatable.Memory().Value())/1e9
+		if cost <= 0 {
+			cost = 1
+		}
+		score := float64(reduction) / cost
+		if bestTemplate == "" || score > bestScore {
+			bestTemplate, bestScore = tmpl.Name, score
+		}
+	}
+	return bestTemplate
+}
+
+// askNodeCounts drives the interactive add-node survey: a single template is
+// asked for its count directly, while multiple templates are first narrowed
+// down with a multi-select before asking a count for each chosen one.
+func askNodeCounts(templates []NodeTemplate, counts map[string]int) error {
+	if len(templates) == 0 {
+		return fmt.Errorf("no new node template configured")
+	}
+	if len(templates) == 1 {
+		num := 0
+		if err := survey.AskOne(&survey.Input{Message: "input node number"}, &num); err != nil {
+			return err
+		}
+		counts[templates[0].Name] = num
+		return nil
+	}
+
+	var templateNames []string
+	for _, tmpl := range templates {
+		templateNames = append(templateNames, tmpl.Name)
+	}
+	var selectedTemplates []string
+	if err := survey.AskOne(&survey.MultiSelect{Message: "select node template(s) to add", Options: templateNames}, &selectedTemplates); err != nil {
+		return err
+	}
+	for _, name := range selectedTemplates {
+		num := 0
+		if err := survey.AskOne(&survey.Input{Message: fmt.Sprintf("input node number for template %q", name)}, &num); err != nil {
+			return err
+		}
+		counts[name] = num
+	}
+	return nil
+}
+
+func totalNodeCount(counts map[string]int) int {
+	total := 0
+	for _, count := range counts {
+		total += count
+	}
+	return total
+}
+
 // report print out scheduling result of pods
 func report(nodeStatuses []simulator.NodeStatus, extendedResources []string, appNameList []string) {
 	reportClusterInfo(nodeStatuses, extendedResources)
@@ -311,6 +580,15 @@ func report(nodeStatuses []simulator.NodeStatus, extendedResources []string, app
 	reportAppInfo(nodeStatuses, appNameList)
 }
 
+// reportClusterInfo prints per-node allocatable/requested tables, including a
+// GPU section when extendedResources asks for it. The GPU section only
+// consumes gpusharecache.NodeGpuInfo already recorded on each node's
+// simontype.AnnoNodeGpuShare annotation (see gpuFitWarningsForNode). Adding
+// per-device fields to NodeGpuInfo, predicate/scoring logic for the
+// open-gpu-share scheduler plugin, and a custom-cluster YAML schema field for
+// authoring per-index GPU model/memory are all out of scope here: this
+// package is a consumer of open-gpu-share's types and scheduling decisions,
+// not their owner, so those changes belong in that package instead.
 func reportClusterInfo(nodeStatuses []simulator.NodeStatus, extendedResources []string) {
 	pterm.FgYellow.Println("Node Info")
 	clusterTable := pterm.DefaultTable.WithHasHeader()
@@ -326,6 +604,7 @@ func reportClusterInfo(nodeStatuses []simulator.NodeStatus, extendedResources []
 		nodeTableHeader = append(nodeTableHeader, []string{
 			"GPU Mem Allocatable",
 			"GPU Mem Requests",
+			"Whole GPUs",
 		}...)
 	}
 	nodeTableHeader = append(nodeTableHeader, []string{
@@ -358,7 +637,9 @@ func reportClusterInfo(nodeStatuses []simulator.NodeStatus, extendedResources []
 		nodeCpuReqFraction := float64(nodeCpuReq.MilliValue()) / float64(allocatable.Cpu().MilliValue()) * 100
 		nodeMemoryReqFraction := float64(nodeMemoryReq.Value()) / float64(allocatable.Memory().Value()) * 100
 		newNode := ""
-		if _, exist := node.Labels[simontype.LabelNewNode]; exist {
+		if template, exist := node.Labels[labelNewNodeTemplate]; exist {
+			newNode = template
+		} else if _, exist := node.Labels[simontype.LabelNewNode]; exist {
 			newNode = "√"
 		}
 
@@ -371,15 +652,18 @@ func reportClusterInfo(nodeStatuses []simulator.NodeStatus, extendedResources []
 		}
 		if containGpu(extendedResources) {
 			nodeGpuMemReq := resource.NewQuantity(0, resource.BinarySI)
+			nodeWholeGpuReq := 0
 			for _, pod := range status.Pods {
 				gpuMem, gpuNum := gpushareutils.GetGpuMemoryAndCountFromPodAnnotation(pod)
 				gpuMemReq := resource.NewQuantity(int64(gpuMem*gpuNum), resource.BinarySI)
 				nodeGpuMemReq.Add(*gpuMemReq)
+				nodeWholeGpuReq += wholeGpuCountForPod(pod)
 			}
 			nodeGpuMemFraction := float64(nodeGpuMemReq.Value()) / float64(allocatable.Name(gpushareutils.ResourceName, resource.BinarySI).Value()) * 100
 			data = append(data, []string{
 				allocatable.Name(gpushareutils.ResourceName, resource.BinarySI).String(),
 				fmt.Sprintf("%s(%d%%)", nodeGpuMemReq.String(), int64(nodeGpuMemFraction)),
+				fmt.Sprintf("%d/%d", nodeWholeGpuReq, allocatable.Name(resourceNvidiaGPU, resource.DecimalSI).Value()),
 			}...)
 		}
 		data = append(data, []string{
@@ -399,10 +683,11 @@ func reportClusterInfo(nodeStatuses []simulator.NodeStatus, extendedResources []
 		pterm.FgYellow.Println("Extended Resource Info")
 		if containGpu(extendedResources) {
 			var podList []*corev1.Pod
+			var gpuFitWarnings []GPUFitWarning
 			pterm.FgYellow.Println("GPU Node Resource")
 			nodeGpuTable := pterm.DefaultTable.WithHasHeader()
 			var nodeGpuTableData [][]string
-			nodeGpuTableData = append(nodeGpuTableData, []string{"Node", "GPU ID", "GPU Request/Capacity", "Pod List"})
+			nodeGpuTableData = append(nodeGpuTableData, []string{"Node", "GPU ID", "GPU Request/Capacity", "Pod List", "Fragmentation"})
 			for _, status := range nodeStatuses {
 				node := status.Node
 				podList = append(podList, status.Pods...)
@@ -412,16 +697,34 @@ func reportClusterInfo(nodeStatuses []simulator.NodeStatus, extendedResources []
 						log.Errorf("failed to unmarshal storage information of node(%s: %v", node.Name, err)
 						continue
 					}
-					nodeGpuMemReq := resource.NewQuantity(0, resource.BinarySI)
-					for _, pod := range status.Pods {
-						gpuMem, gpuNum := gpushareutils.GetGpuMemoryAndCountFromPodAnnotation(pod)
-						gpuMemReq := resource.NewQuantity(int64(gpuMem*gpuNum), resource.BinarySI)
-						nodeGpuMemReq.Add(*gpuMemReq)
+					// Node-level capacity/usage is the sum of each device's own
+					// GpuTotalMemory/GpuUsedMemory rather than a single
+					// nodeGpuInfo.GpuTotalMemory value, since devices on the
+					// same node are not guaranteed to be identical.
+					nodeTotalGpuMem := resource.NewQuantity(0, resource.BinarySI)
+					nodeUsedGpuMem := resource.NewQuantity(0, resource.BinarySI)
+					var maxFreeDeviceBlock int64
+					var totalFreeMem int64
+					for idx := 0; idx < len(nodeGpuInfo.DevsBrief); idx += 1 {
+						if deviceInfoBrief, ok := nodeGpuInfo.DevsBrief[idx]; ok {
+							nodeTotalGpuMem.Add(deviceInfoBrief.GpuTotalMemory)
+							nodeUsedGpuMem.Add(deviceInfoBrief.GpuUsedMemory)
+							free := deviceInfoBrief.GpuTotalMemory.Value() - deviceInfoBrief.GpuUsedMemory.Value()
+							if free > maxFreeDeviceBlock {
+								maxFreeDeviceBlock = free
+							}
+							totalFreeMem += free
+						}
+					}
+					gpuReqCapFraction := float64(nodeUsedGpuMem.Value()) / float64(nodeTotalGpuMem.Value()) * 100
+					gpuReqCapStr := fmt.Sprintf("%s/%s(%d%%)", nodeUsedGpuMem.String(), nodeTotalGpuMem.String(), int(gpuReqCapFraction))
+					fragmentationScore := 0.0
+					if totalFreeMem > 0 {
+						fragmentationScore = 1 - float64(maxFreeDeviceBlock)/float64(totalFreeMem)
 					}
-					gpuReqCapFraction := float64(nodeGpuMemReq.Value()) / float64(nodeGpuInfo.GpuTotalMemory.Value()) * 100
-					gpuReqCapStr := fmt.Sprintf("%s/%s(%d%%)", nodeGpuMemReq.String(), nodeGpuInfo.GpuTotalMemory.String(), int(gpuReqCapFraction))
-					nodeOutputLine := []string{fmt.Sprintf("%s (%s)", node.Name, nodeGpuInfo.GpuModel), fmt.Sprintf("%d GPUs", nodeGpuInfo.GpuCount), gpuReqCapStr, fmt.Sprintf("%d Pods", nodeGpuInfo.NumPods)}
+					nodeOutputLine := []string{fmt.Sprintf("%s (%s)", node.Name, nodeGpuInfo.GpuModel), fmt.Sprintf("%d GPUs", nodeGpuInfo.GpuCount), gpuReqCapStr, fmt.Sprintf("%d Pods", nodeGpuInfo.NumPods), fmt.Sprintf("%.2f", fragmentationScore)}
 					nodeGpuTableData = append(nodeGpuTableData, nodeOutputLine)
+					gpuFitWarnings = append(gpuFitWarnings, gpuFitWarningsForNode(node, status.Pods, &nodeGpuInfo)...)
 
 					for idx := 0; idx < len(nodeGpuInfo.DevsBrief); idx += 1 {
 						if deviceInfoBrief, ok := nodeGpuInfo.DevsBrief[idx]; ok {
@@ -432,7 +735,8 @@ func reportClusterInfo(nodeStatuses []simulator.NodeStatus, extendedResources []
 							devUsedGpuMem := deviceInfoBrief.GpuUsedMemory
 							devReqCapFraction := float64(devUsedGpuMem.Value()) / float64(devTotalGpuMem.Value()) * 100
 							devReqCapStr := fmt.Sprintf("%s/%s(%d%%)", devUsedGpuMem.String(), devTotalGpuMem.String(), int(devReqCapFraction))
-							nodeOutputLineDev := []string{fmt.Sprintf("%s (%s)", node.Name, nodeGpuInfo.GpuModel), fmt.Sprintf("%d", idx), devReqCapStr, fmt.Sprintf("%s", deviceInfoBrief.PodList)}
+							devFreeBlock := resource.NewQuantity(devTotalGpuMem.Value()-devUsedGpuMem.Value(), resource.BinarySI)
+							nodeOutputLineDev := []string{fmt.Sprintf("%s (%s)", node.Name, nodeGpuInfo.GpuModel), fmt.Sprintf("%d", idx), devReqCapStr, fmt.Sprintf("%s", deviceInfoBrief.PodList), devFreeBlock.String()}
 							nodeGpuTableData = append(nodeGpuTableData, nodeOutputLineDev)
 						}
 					}
@@ -463,6 +767,14 @@ func reportClusterInfo(nodeStatuses []simulator.NodeStatus, extendedResources []
 				pterm.FgRed.Printf("fail to render cluster table: %s\n", err.Error())
 				os.Exit(1)
 			}
+
+			if len(gpuFitWarnings) != 0 {
+				pterm.FgRed.Println("\nGPU Fit Warnings")
+				for _, w := range gpuFitWarnings {
+					pterm.FgRed.Printf("  pod %s/%s on node %s requests %s but GPU %s only has %s total\n",
+						w.Namespace, w.Name, w.Node, w.RequestedMemory, w.GPUIndex, w.DeviceCapacity)
+				}
+			}
 		}
 	}
 }
@@ -499,7 +811,7 @@ func reportNodeInfo(nodeStatuses []simulator.NodeStatus, extendedResources []str
 	if containGpu(extendedResources) {
 		header = append(header, "GPU Mem Requests")
 	}
-	header = append(header, "APP Name")
+	header = append(header, "APP Name", "NUMA Alignment")
 
 	for _, status := range nodeStatuses {
 		node := status.Node
@@ -511,6 +823,10 @@ func reportNodeInfo(nodeStatuses []simulator.NodeStatus, extendedResources []str
 		var podTableData [][]string
 		podTableData = append(podTableData, header)
 		allocatable := node.Status.Allocatable
+		topologyHint, err := simulator.ParseTopologyHint(node)
+		if err != nil {
+			log.Errorf("%v", err)
+		}
 		for _, pod := range status.Pods {
 			if pod.Spec.NodeName != node.Name {
 				continue
@@ -540,6 +856,24 @@ func reportNodeInfo(nodeStatuses []simulator.NodeStatus, extendedResources []str
 			}
 
 			data = append(data, appname)
+
+			numaAlignment := "-"
+			if policy := simulator.PodTopologyPolicy(pod); policy != "" {
+				gpuIndex := -1
+				if idx, convErr := strconv.Atoi(gpushareutils.GetGpuIdFromAnnotation(pod)); convErr == nil {
+					gpuIndex = idx
+				}
+				aligned, reason := simulator.IsNumaAligned(topologyHint, cpuReq.MilliValue(), memoryReq.Value(), gpuIndex)
+				if aligned {
+					numaAlignment = "aligned"
+				} else if policy == simulator.TopologyPolicyBestEffort {
+					numaAlignment = fmt.Sprintf("best-effort (%s)", reason)
+				} else {
+					numaAlignment = fmt.Sprintf("unaligned (%s)", reason)
+				}
+			}
+			data = append(data, numaAlignment)
+
 			podTableData = append(podTableData, data)
 		}
 		if err := podTable.WithData(podTableData).Render(); err != nil {
@@ -616,6 +950,7 @@ func satisfyResourceSetting(nodeStatuses []simulator.NodeStatus) (bool, string,
 	var maxcpu int = 100
 	var maxmem int = 100
 	var maxvg int = 100
+	var maxgpu int = 100
 	if str := os.Getenv(simontype.EnvMaxCPU); str != "" {
 		if maxcpu, err = strconv.Atoi(str); err != nil {
 			return false, "", fmt.Errorf("failed to convert env %s to int: %s ", simontype.EnvMaxCPU, err.Error())
@@ -643,14 +978,26 @@ func satisfyResourceSetting(nodeStatuses []simulator.NodeStatus) (bool, string,
 		}
 	}
 
+	if str := os.Getenv(simontype.EnvMaxGPU); str != "" {
+		if maxgpu, err = strconv.Atoi(str); err != nil {
+			return false, "", fmt.Errorf("failed to convert env %s to int: %s ", simontype.EnvMaxGPU, err.Error())
+		}
+		if maxgpu > 100 || maxgpu < 0 {
+			maxgpu = 100
+		}
+	}
+
 	totalAllocatableResource := map[corev1.ResourceName]*resource.Quantity{
-		corev1.ResourceCPU:    resource.NewQuantity(0, resource.DecimalSI),
-		corev1.ResourceMemory: resource.NewQuantity(0, resource.DecimalSI),
+		corev1.ResourceCPU:         resource.NewQuantity(0, resource.DecimalSI),
+		corev1.ResourceMemory:      resource.NewQuantity(0, resource.DecimalSI),
+		gpushareutils.ResourceName: resource.NewQuantity(0, resource.BinarySI),
 	}
 	totalUsedResource := map[corev1.ResourceName]*resource.Quantity{
-		corev1.ResourceCPU:    resource.NewQuantity(0, resource.DecimalSI),
-		corev1.ResourceMemory: resource.NewQuantity(0, resource.DecimalSI),
+		corev1.ResourceCPU:         resource.NewQuantity(0, resource.DecimalSI),
+		corev1.ResourceMemory:      resource.NewQuantity(0, resource.DecimalSI),
+		gpushareutils.ResourceName: resource.NewQuantity(0, resource.BinarySI),
 	}
+	var totalWholeGpuAllocatable, totalWholeGpuUsed int64
 	var allPods []corev1.Pod
 	for _, status := range nodeStatuses {
 		for _, pod := range status.Pods {
@@ -662,10 +1009,17 @@ func satisfyResourceSetting(nodeStatuses []simulator.NodeStatus) (bool, string,
 		node := status.Node
 		totalAllocatableResource[corev1.ResourceCPU].Add(*node.Status.Allocatable.Cpu())
 		totalAllocatableResource[corev1.ResourceMemory].Add(*node.Status.Allocatable.Memory())
+		totalAllocatableResource[gpushareutils.ResourceName].Add(*node.Status.Allocatable.Name(gpushareutils.ResourceName, resource.BinarySI))
+		totalWholeGpuAllocatable += node.Status.Allocatable.Name(resourceNvidiaGPU, resource.DecimalSI).Value()
 
 		reqs, _ := utils.GetPodsTotalRequestsAndLimitsByNodeName(allPods, node.Name)
 		totalUsedResource[corev1.ResourceCPU].Add(reqs[corev1.ResourceCPU])
 		totalUsedResource[corev1.ResourceMemory].Add(reqs[corev1.ResourceMemory])
+		for _, pod := range status.Pods {
+			gpuMem, gpuNum := gpushareutils.GetGpuMemoryAndCountFromPodAnnotation(pod)
+			totalUsedResource[gpushareutils.ResourceName].Add(*resource.NewQuantity(int64(gpuMem*gpuNum), resource.BinarySI))
+			totalWholeGpuUsed += int64(wholeGpuCountForPod(pod))
+		}
 	}
 
 	cpuOccupancyRate := int(float64(totalUsedResource[corev1.ResourceCPU].MilliValue()) / float64(totalAllocatableResource[corev1.ResourceCPU].MilliValue()) * 100)
@@ -676,10 +1030,70 @@ func satisfyResourceSetting(nodeStatuses []simulator.NodeStatus) (bool, string,
 	if memoryOccupancyRate > maxmem {
 		return false, fmt.Sprintf("the average occupancy rate(%d%%) of memory goes beyond the env setting(%d%%)\n", memoryOccupancyRate, maxmem), nil
 	}
+	if totalAllocatableResource[gpushareutils.ResourceName].Value() > 0 {
+		gpuMemOccupancyRate := int(float64(totalUsedResource[gpushareutils.ResourceName].Value()) / float64(totalAllocatableResource[gpushareutils.ResourceName].Value()) * 100)
+		if gpuMemOccupancyRate > maxgpu {
+			return false, fmt.Sprintf("the average occupancy rate(%d%%) of gpu memory goes beyond the env setting(%d%%)\n", gpuMemOccupancyRate, maxgpu), nil
+		}
+	}
+	if totalWholeGpuAllocatable > 0 {
+		wholeGpuOccupancyRate := int(float64(totalWholeGpuUsed) / float64(totalWholeGpuAllocatable) * 100)
+		if wholeGpuOccupancyRate > maxgpu {
+			return false, fmt.Sprintf("the average occupancy rate(%d%%) of whole gpus goes beyond the env setting(%d%%)\n", wholeGpuOccupancyRate, maxgpu), nil
+		}
+	}
+
+	if str := os.Getenv(simontype.EnvMinNumaAlignmentPct); str != "" {
+		minNumaAlignmentPct, convErr := strconv.Atoi(str)
+		if convErr != nil {
+			return false, "", fmt.Errorf("failed to convert env %s to int: %s ", simontype.EnvMinNumaAlignmentPct, convErr.Error())
+		}
+		alignedPods, topologyAwarePods := 0, 0
+		for _, status := range nodeStatuses {
+			topologyHint, parseErr := simulator.ParseTopologyHint(status.Node)
+			if parseErr != nil {
+				return false, "", parseErr
+			}
+			for _, pod := range status.Pods {
+				policy := simulator.PodTopologyPolicy(pod)
+				if policy == "" {
+					continue
+				}
+				topologyAwarePods++
+				req, _ := resourcehelper.PodRequestsAndLimits(pod)
+				gpuIndex := -1
+				if idx, convErr := strconv.Atoi(gpushareutils.GetGpuIdFromAnnotation(pod)); convErr == nil {
+					gpuIndex = idx
+				}
+				if aligned, _ := simulator.IsNumaAligned(topologyHint, req[corev1.ResourceCPU].MilliValue(), req[corev1.ResourceMemory].Value(), gpuIndex); aligned {
+					alignedPods++
+				}
+			}
+		}
+		if topologyAwarePods > 0 {
+			numaAlignmentPct := int(float64(alignedPods) / float64(topologyAwarePods) * 100)
+			if numaAlignmentPct < minNumaAlignmentPct {
+				return false, fmt.Sprintf("the NUMA alignment rate(%d%%) of topology-aware pods is below the env setting(%d%%)\n", numaAlignmentPct, minNumaAlignmentPct), nil
+			}
+		}
+	}
 
 	return true, "", nil
 }
 
+// wholeGpuCountForPod returns the number of exclusively-held GPUs (requested
+// via the device-plugin resource "nvidia.com/gpu") across all containers of
+// pod. It is independent of the shared-memory/vGPU accounting done by
+// gpushareutils, since a whole-GPU request never carries a gpu-share
+// annotation.
+func wholeGpuCountForPod(pod *corev1.Pod) int {
+	req, _ := resourcehelper.PodRequestsAndLimits(pod)
+	if q, ok := req[resourceNvidiaGPU]; ok {
+		return int(q.Value())
+	}
+	return 0
+}
+
 func containGpu(extendedResources []string) bool {
 	for _, res := range extendedResources {
 		if res == "gpu" {
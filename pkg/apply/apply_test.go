@@ -0,0 +1,47 @@
+package apply
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func nodeTemplate(name, cpu, memory string) NodeTemplate {
+	return NodeTemplate{
+		Name: name,
+		Node: &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Status: corev1.NodeStatus{
+				Allocatable: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse(cpu),
+					corev1.ResourceMemory: resource.MustParse(memory),
+				},
+			},
+		},
+	}
+}
+
+func TestBestScalingTemplatePrefersHigherReductionPerCost(t *testing.T) {
+	templates := []NodeTemplate{
+		nodeTemplate("small", "2", "4Gi"),
+		nodeTemplate("large", "8", "16Gi"),
+	}
+	// "small" reduces fewer pods in absolute terms, but at a much lower
+	// resource cost, so its reduction-per-cost score should win.
+	reductions := map[string]int{"small": 2, "large": 3}
+
+	if got := bestScalingTemplate(templates, reductions); got != "small" {
+		t.Fatalf("bestScalingTemplate() = %q, want %q", got, "small")
+	}
+}
+
+func TestBestScalingTemplateNoProgress(t *testing.T) {
+	templates := []NodeTemplate{nodeTemplate("small", "2", "4Gi")}
+	reductions := map[string]int{"small": 0}
+
+	if got := bestScalingTemplate(templates, reductions); got != "" {
+		t.Fatalf("bestScalingTemplate() = %q, want empty string when no template makes progress", got)
+	}
+}
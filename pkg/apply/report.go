@@ -0,0 +1,264 @@
+package apply
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	resourcehelper "k8s.io/kubectl/pkg/util/resource"
+	"sigs.k8s.io/yaml"
+
+	"github.com/alibaba/open-simulator/pkg/simulator"
+	simontype "github.com/alibaba/open-simulator/pkg/type"
+	gpusharecache "github.com/alibaba/open-simulator/pkg/type/open-gpu-share/cache"
+	gpushareutils "github.com/alibaba/open-simulator/pkg/type/open-gpu-share/utils"
+)
+
+const (
+	OutputFormatTable = "table"
+	OutputFormatJSON  = "json"
+	OutputFormatYAML  = "yaml"
+)
+
+// SimulationReport is the machine-readable equivalent of the pterm tables
+// printed by report(), so callers that drive Applier from a script, CI job
+// or a controller don't have to scrape terminal output.
+type SimulationReport struct {
+	Scheduled  bool `json:"scheduled"`
+	AddedNodes int  `json:"addedNodes"`
+
+	Cluster         ClusterReport        `json:"cluster"`
+	Nodes           []NodeReport         `json:"nodes"`
+	Apps            []AppReport          `json:"apps,omitempty"`
+	UnscheduledPods []UnscheduledPodInfo `json:"unscheduledPods,omitempty"`
+	GPUFitWarnings  []GPUFitWarning      `json:"gpuFitWarnings,omitempty"`
+}
+
+// GPUFitWarning flags a pod whose gpu-share annotation requests more memory
+// than the single device it was assigned to can ever hold. It can only be
+// computed after the fact from the device's declared total capacity in
+// simontype.AnnoNodeGpuShare: the actual vGPU-to-device matching decision
+// belongs to the open-gpu-share scheduler plugin, which this package only
+// consumes the annotations of and does not own, so it can surface an
+// impossible assignment but can't prevent one.
+type GPUFitWarning struct {
+	Namespace       string `json:"namespace"`
+	Name            string `json:"name"`
+	Node            string `json:"node"`
+	GPUIndex        string `json:"gpuIndex"`
+	RequestedMemory string `json:"requestedMemory"`
+	DeviceCapacity  string `json:"deviceCapacity"`
+}
+
+// gpuFitWarningsForNode returns one GPUFitWarning per pod in pods whose own
+// gpu-share memory request already exceeds the total capacity of the device
+// nodeGpuInfo says it was assigned to.
+func gpuFitWarningsForNode(node *corev1.Node, pods []*corev1.Pod, nodeGpuInfo *gpusharecache.NodeGpuInfo) []GPUFitWarning {
+	if nodeGpuInfo == nil {
+		return nil
+	}
+	var warnings []GPUFitWarning
+	for _, pod := range pods {
+		gpuMem, gpuNum := gpushareutils.GetGpuMemoryAndCountFromPodAnnotation(pod)
+		if gpuNum == 0 {
+			continue
+		}
+		idx, err := strconv.Atoi(gpushareutils.GetGpuIdFromAnnotation(pod))
+		if err != nil {
+			continue
+		}
+		deviceInfoBrief, ok := nodeGpuInfo.DevsBrief[idx]
+		if !ok {
+			continue
+		}
+		requested := resource.NewQuantity(int64(gpuMem*gpuNum), resource.BinarySI)
+		if requested.Value() > deviceInfoBrief.GpuTotalMemory.Value() {
+			warnings = append(warnings, GPUFitWarning{
+				Namespace:       pod.Namespace,
+				Name:            pod.Name,
+				Node:            node.Name,
+				GPUIndex:        strconv.Itoa(idx),
+				RequestedMemory: requested.String(),
+				DeviceCapacity:  deviceInfoBrief.GpuTotalMemory.String(),
+			})
+		}
+	}
+	return warnings
+}
+
+// ClusterReport summarizes allocatable vs. requested resources across the
+// whole simulated cluster.
+type ClusterReport struct {
+	CPUAllocatable    string `json:"cpuAllocatable"`
+	CPURequested      string `json:"cpuRequested"`
+	MemoryAllocatable string `json:"memoryAllocatable"`
+	MemoryRequested   string `json:"memoryRequested"`
+	GPUMemAllocatable string `json:"gpuMemAllocatable,omitempty"`
+	GPUMemRequested   string `json:"gpuMemRequested,omitempty"`
+}
+
+// NodeReport is the per-node placement result of a simulation.
+type NodeReport struct {
+	Name              string                     `json:"name"`
+	NewNode           bool                       `json:"newNode"`
+	NewNodeTemplate   string                     `json:"newNodeTemplate,omitempty"`
+	CPUAllocatable    string                     `json:"cpuAllocatable"`
+	CPURequested      string                     `json:"cpuRequested"`
+	MemoryAllocatable string                     `json:"memoryAllocatable"`
+	MemoryRequested   string                     `json:"memoryRequested"`
+	PodCount          int                        `json:"podCount"`
+	GPU               *gpusharecache.NodeGpuInfo `json:"gpu,omitempty"`
+	Pods              []PodPlacement             `json:"pods,omitempty"`
+}
+
+// PodPlacement records where a single pod landed.
+type PodPlacement struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	AppName   string `json:"appName,omitempty"`
+}
+
+// AppReport groups the pods belonging to one requested application.
+type AppReport struct {
+	Name string         `json:"name"`
+	Pods []PodPlacement `json:"pods"`
+}
+
+// UnscheduledPodInfo mirrors simulator.UnscheduledPod in a serializable form.
+type UnscheduledPodInfo struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	Reason    string `json:"reason"`
+}
+
+// buildSimulationReport turns a simulator.SimulateResult into a
+// SimulationReport. It never touches pterm/survey so it is safe to call for
+// json/yaml output without a terminal.
+func buildSimulationReport(result *simulator.SimulateResult, extendedResources []string, appNameList []string, scheduled bool, addedNodes int) *SimulationReport {
+	r := &SimulationReport{
+		Scheduled:  scheduled,
+		AddedNodes: addedNodes,
+	}
+
+	appPods := make(map[string][]PodPlacement)
+	cpuAllocatable := resource.NewQuantity(0, resource.DecimalSI)
+	cpuRequested := resource.NewQuantity(0, resource.DecimalSI)
+	memAllocatable := resource.NewQuantity(0, resource.DecimalSI)
+	memRequested := resource.NewQuantity(0, resource.DecimalSI)
+	gpuMemAllocatable := resource.NewQuantity(0, resource.BinarySI)
+	gpuMemRequested := resource.NewQuantity(0, resource.BinarySI)
+	hasGpu := containGpu(extendedResources)
+
+	for _, status := range result.NodeStatus {
+		node := status.Node
+		allocatable := node.Status.Allocatable
+		nodeReq := resource.NewQuantity(0, resource.DecimalSI)
+		nodeMemReq := resource.NewQuantity(0, resource.DecimalSI)
+		for _, pod := range status.Pods {
+			req, _ := resourcehelper.PodRequestsAndLimits(pod)
+			nodeReq.Add(req[corev1.ResourceCPU])
+			nodeMemReq.Add(req[corev1.ResourceMemory])
+
+			appName := pod.Labels[simontype.LabelAppName]
+			placement := PodPlacement{Namespace: pod.Namespace, Name: pod.Name, AppName: appName}
+			if appName != "" {
+				appPods[appName] = append(appPods[appName], placement)
+			}
+		}
+		cpuAllocatable.Add(*allocatable.Cpu())
+		cpuRequested.Add(*nodeReq)
+		memAllocatable.Add(*allocatable.Memory())
+		memRequested.Add(*nodeMemReq)
+
+		nodeReport := NodeReport{
+			Name:              node.Name,
+			CPUAllocatable:    allocatable.Cpu().String(),
+			CPURequested:      nodeReq.String(),
+			MemoryAllocatable: allocatable.Memory().String(),
+			MemoryRequested:   nodeMemReq.String(),
+			PodCount:          len(status.Pods),
+		}
+		if template, exist := node.Labels[labelNewNodeTemplate]; exist {
+			nodeReport.NewNode = true
+			nodeReport.NewNodeTemplate = template
+		} else if _, exist := node.Labels[simontype.LabelNewNode]; exist {
+			nodeReport.NewNode = true
+		}
+		for _, pod := range status.Pods {
+			nodeReport.Pods = append(nodeReport.Pods, PodPlacement{
+				Namespace: pod.Namespace,
+				Name:      pod.Name,
+				AppName:   pod.Labels[simontype.LabelAppName],
+			})
+		}
+
+		if hasGpu {
+			gpuMemAllocatable.Add(*allocatable.Name(gpushareutils.ResourceName, resource.BinarySI))
+			for _, pod := range status.Pods {
+				gpuMem, gpuNum := gpushareutils.GetGpuMemoryAndCountFromPodAnnotation(pod)
+				gpuMemRequested.Add(*resource.NewQuantity(int64(gpuMem*gpuNum), resource.BinarySI))
+			}
+			if nodeGpuInfoStr, exist := node.Annotations[simontype.AnnoNodeGpuShare]; exist {
+				var nodeGpuInfo gpusharecache.NodeGpuInfo
+				if err := json.Unmarshal([]byte(nodeGpuInfoStr), &nodeGpuInfo); err == nil {
+					nodeReport.GPU = &nodeGpuInfo
+					r.GPUFitWarnings = append(r.GPUFitWarnings, gpuFitWarningsForNode(node, status.Pods, &nodeGpuInfo)...)
+				}
+			}
+		}
+
+		r.Nodes = append(r.Nodes, nodeReport)
+	}
+
+	r.Cluster = ClusterReport{
+		CPUAllocatable:    cpuAllocatable.String(),
+		CPURequested:      cpuRequested.String(),
+		MemoryAllocatable: memAllocatable.String(),
+		MemoryRequested:   memRequested.String(),
+	}
+	if hasGpu {
+		r.Cluster.GPUMemAllocatable = gpuMemAllocatable.String()
+		r.Cluster.GPUMemRequested = gpuMemRequested.String()
+	}
+
+	for _, name := range appNameList {
+		r.Apps = append(r.Apps, AppReport{Name: name, Pods: appPods[name]})
+	}
+
+	for _, pod := range result.UnscheduledPods {
+		r.UnscheduledPods = append(r.UnscheduledPods, UnscheduledPodInfo{
+			Namespace: pod.Pod.Namespace,
+			Name:      pod.Pod.Name,
+			Reason:    pod.Reason,
+		})
+	}
+
+	return r
+}
+
+// writeReport serializes report as json or yaml and writes it to
+// applier.outputFile, falling back to stdout.
+func (applier *Applier) writeReport(report *SimulationReport) error {
+	var data []byte
+	var err error
+	switch applier.outputFormat {
+	case OutputFormatJSON:
+		data, err = json.MarshalIndent(report, "", "  ")
+	case OutputFormatYAML:
+		data, err = yaml.Marshal(report)
+	default:
+		return fmt.Errorf("unsupported output format: %s", applier.outputFormat)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to marshal simulation report: %v", err)
+	}
+
+	if applier.outputFile != nil {
+		_, err = applier.outputFile.Write(data)
+		return err
+	}
+	fmt.Println(string(data))
+	return nil
+}
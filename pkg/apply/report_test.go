@@ -0,0 +1,79 @@
+package apply
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/alibaba/open-simulator/pkg/simulator"
+	simontype "github.com/alibaba/open-simulator/pkg/type"
+)
+
+func testNode(name string, cpu, memory string) *corev1.Node {
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Status: corev1.NodeStatus{
+			Allocatable: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse(cpu),
+				corev1.ResourceMemory: resource.MustParse(memory),
+			},
+		},
+	}
+}
+
+func testPod(namespace, name, appName, cpu, memory string) *corev1.Pod {
+	labels := map[string]string{}
+	if appName != "" {
+		labels[simontype.LabelAppName] = appName
+	}
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name, Labels: labels},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{
+				Resources: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{
+						corev1.ResourceCPU:    resource.MustParse(cpu),
+						corev1.ResourceMemory: resource.MustParse(memory),
+					},
+				},
+			}},
+		},
+	}
+}
+
+func TestBuildSimulationReportAggregatesClusterAndNodes(t *testing.T) {
+	result := &simulator.SimulateResult{
+		NodeStatus: []simulator.NodeStatus{
+			{
+				Node: testNode("node-1", "4", "8Gi"),
+				Pods: []*corev1.Pod{
+					testPod("default", "web-1", "web", "1", "1Gi"),
+					testPod("default", "web-2", "web", "1", "1Gi"),
+				},
+			},
+		},
+		UnscheduledPods: []simulator.UnscheduledPod{
+			{Pod: testPod("default", "pending-1", "web", "1", "1Gi"), Reason: "insufficient cpu"},
+		},
+	}
+
+	report := buildSimulationReport(result, nil, []string{"web"}, true, 0)
+
+	if report.Cluster.CPUAllocatable != "4" {
+		t.Errorf("CPUAllocatable = %q, want %q", report.Cluster.CPUAllocatable, "4")
+	}
+	if report.Cluster.CPURequested != "2" {
+		t.Errorf("CPURequested = %q, want %q", report.Cluster.CPURequested, "2")
+	}
+	if len(report.Nodes) != 1 || report.Nodes[0].PodCount != 2 {
+		t.Fatalf("expected 1 node with 2 pods, got %+v", report.Nodes)
+	}
+	if len(report.Apps) != 1 || len(report.Apps[0].Pods) != 2 {
+		t.Fatalf("expected app %q to have 2 pods, got %+v", "web", report.Apps)
+	}
+	if len(report.UnscheduledPods) != 1 || report.UnscheduledPods[0].Name != "pending-1" {
+		t.Fatalf("expected 1 unscheduled pod named pending-1, got %+v", report.UnscheduledPods)
+	}
+}
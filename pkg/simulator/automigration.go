@@ -0,0 +1,336 @@
+package simulator
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+)
+
+// DefaultUnschedulableThreshold is how long a pod must have been reported
+// PodScheduled=False/Unschedulable before a built-in AutoMigrationPlugin
+// considers it a candidate for eviction and reschedule.
+const DefaultUnschedulableThreshold = 5 * time.Minute
+
+// AutoMigrationPlugin estimates how many replicas of a workload are stuck
+// unschedulable and should be evicted and rescheduled elsewhere, analogous to
+// kubeadmiral's auto-migration controller. Implementations are registered by
+// workload kind via WithAutoMigrationPlugins.
+type AutoMigrationPlugin interface {
+	// Name identifies the plugin, e.g. for logging.
+	Name() string
+	// EstimateUnschedulable inspects pods owned by workload and returns how
+	// many of them should be migrated, along with a human-readable reason.
+	// It returns (0, "") when workload is not of a type this plugin handles,
+	// or none of its pods qualify.
+	EstimateUnschedulable(workload metav1.Object, pods []*corev1.Pod) (unschedulable int, reason string)
+}
+
+// AutoMigrationPluginMap keys AutoMigrationPlugins by the workload kind they
+// handle, e.g. "Deployment". WithAutoMigrationPlugins merges into the
+// built-in Deployment/ReplicaSet/StatefulSet plugins, so callers can override
+// one of them. RunAutoMigration only evaluates the Deployments, ReplicaSets
+// and StatefulSets of its ResourceTypes, so a plugin keyed by any other kind
+// is never called.
+type AutoMigrationPluginMap map[string]AutoMigrationPlugin
+
+// MigrationResult reports the outcome of one RunAutoMigration pass.
+type MigrationResult struct {
+	// Migrated lists the pods that were evicted because their owning
+	// workload's plugin judged them stuck unschedulable.
+	Migrated []MigratedPod
+	// SimulateResult is the result of rescheduling the migrated pods, so
+	// callers can see whether the cluster can absorb the churn.
+	SimulateResult *SimulateResult
+}
+
+// MigratedPod records why one pod was evicted for auto-migration.
+type MigratedPod struct {
+	Namespace string
+	Name      string
+	Workload  string
+	Reason    string
+}
+
+// RunAutoMigration evaluates the workloads in resourceList with the
+// registered AutoMigrationPlugins, evicts the replicas each plugin judges
+// stuck unschedulable, and re-runs schedulePods with those pods recreated so
+// callers can see whether the cluster can absorb the churn.
+func (sim *Simulator) RunAutoMigration(ctx context.Context, resourceList ResourceTypes) (*MigrationResult, error) {
+	logger := klog.FromContext(ctx)
+
+	allPods, err := sim.fakeclient.CoreV1().Pods(corev1.NamespaceAll).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods for auto-migration: %v", err)
+	}
+	pods := make([]*corev1.Pod, len(allPods.Items))
+	for i := range allPods.Items {
+		pods[i] = &allPods.Items[i]
+	}
+	podIndex := buildPodOwnerIndex(pods)
+	rsOwners := replicaSetOwners(resourceList.ReplicaSets)
+
+	var migrated []MigratedPod
+	var toEvict []*corev1.Pod
+
+	evaluate := func(kind string, workload metav1.Object) {
+		plugin, ok := sim.autoMigrationPlugins[kind]
+		if !ok {
+			return
+		}
+		pods := workloadPods(kind, workload, podIndex, rsOwners)
+		if len(pods) == 0 {
+			return
+		}
+		n, reason := plugin.EstimateUnschedulable(workload, pods)
+		if n <= 0 {
+			return
+		}
+		if n > len(pods) {
+			n = len(pods)
+		}
+		// Evict the longest-unschedulable replicas first.
+		unschedulableSince := func(pod *corev1.Pod) (time.Time, bool) {
+			for _, cond := range pod.Status.Conditions {
+				if cond.Type == corev1.PodScheduled && cond.Status == corev1.ConditionFalse && cond.Reason == corev1.PodReasonUnschedulable {
+					return cond.LastTransitionTime.Time, true
+				}
+			}
+			return time.Time{}, false
+		}
+		sort.SliceStable(pods, func(i, j int) bool {
+			ti, oki := unschedulableSince(pods[i])
+			tj, okj := unschedulableSince(pods[j])
+			return oki && (!okj || ti.Before(tj))
+		})
+		logger.Info("auto-migration selected replicas for eviction",
+			"kind", kind, "workload", klog.KRef(workload.GetNamespace(), workload.GetName()), "count", n, "reason", reason)
+		for i := 0; i < n; i++ {
+			migrated = append(migrated, MigratedPod{
+				Namespace: pods[i].Namespace,
+				Name:      pods[i].Name,
+				Workload:  workload.GetName(),
+				Reason:    reason,
+			})
+			toEvict = append(toEvict, pods[i])
+		}
+	}
+
+	for i := range resourceList.Deployments {
+		evaluate("Deployment", resourceList.Deployments[i])
+	}
+	for i := range resourceList.ReplicaSets {
+		evaluate("ReplicaSet", resourceList.ReplicaSets[i])
+	}
+	for i := range resourceList.StatefulSets {
+		evaluate("StatefulSet", resourceList.StatefulSets[i])
+	}
+
+	if len(toEvict) == 0 {
+		return &MigrationResult{SimulateResult: &SimulateResult{NodeStatus: sim.getClusterNodeStatus()}}, nil
+	}
+
+	for _, pod := range toEvict {
+		if err := sim.fakeclient.CoreV1().Pods(pod.Namespace).Delete(ctx, pod.Name, metav1.DeleteOptions{}); err != nil {
+			return nil, fmt.Errorf("failed to evict pod (%s/%s) for auto-migration: %v", pod.Namespace, pod.Name, err)
+		}
+	}
+
+	rescheduled := make([]*corev1.Pod, len(toEvict))
+	for i, pod := range toEvict {
+		rescheduled[i] = rebindablePod(pod)
+	}
+	failedPods, err := sim.schedulePods(ctx, rescheduled)
+	if err != nil {
+		return nil, err
+	}
+
+	return &MigrationResult{
+		Migrated: migrated,
+		SimulateResult: &SimulateResult{
+			UnscheduledPods: failedPods,
+			NodeStatus:      sim.getClusterNodeStatus(),
+		},
+	}, nil
+}
+
+// ownerKey identifies a workload an AutoMigrationPlugin can be evaluated
+// against. It is keyed on namespace/kind/name rather than UID: objects
+// parsed from a custom-cluster YAML fixture typically have no metadata.uid
+// set, which would otherwise collapse every workload's pods into a single
+// UID("") bucket shared across unrelated Deployments/ReplicaSets/StatefulSets.
+type ownerKey struct {
+	namespace string
+	kind      string
+	name      string
+}
+
+func workloadOwnerKey(kind string, workload metav1.Object) ownerKey {
+	return ownerKey{namespace: workload.GetNamespace(), kind: kind, name: workload.GetName()}
+}
+
+// buildPodOwnerIndex indexes pods by the namespace/kind/name of each of
+// their owner references, so a workload's pods can be looked up without
+// relying on metadata.uid being populated.
+func buildPodOwnerIndex(pods []*corev1.Pod) map[ownerKey][]*corev1.Pod {
+	index := make(map[ownerKey][]*corev1.Pod)
+	for _, pod := range pods {
+		for _, ref := range pod.OwnerReferences {
+			key := ownerKey{namespace: pod.Namespace, kind: ref.Kind, name: ref.Name}
+			index[key] = append(index[key], pod)
+		}
+	}
+	return index
+}
+
+// replicaSetOwners maps each ReplicaSet to the ownerKey of its controlling
+// Deployment, so a Deployment's replicas can be reconciled against the pods
+// that actually get created under its ReplicaSets instead of pods owned by
+// the Deployment directly, which is not how Kubernetes wires ownership.
+func replicaSetOwners(replicaSets []*appsv1.ReplicaSet) map[ownerKey]ownerKey {
+	owners := make(map[ownerKey]ownerKey)
+	for _, rs := range replicaSets {
+		for _, ref := range rs.OwnerReferences {
+			if ref.Kind == "Deployment" {
+				owners[workloadOwnerKey("ReplicaSet", rs)] = ownerKey{namespace: rs.Namespace, kind: ref.Kind, name: ref.Name}
+			}
+		}
+	}
+	return owners
+}
+
+// workloadPods returns the pods belonging to workload, walking the
+// ReplicaSet indirection for Deployments since pods are owned by the
+// ReplicaSet a Deployment manages, not the Deployment itself.
+func workloadPods(kind string, workload metav1.Object, podIndex map[ownerKey][]*corev1.Pod, rsOwners map[ownerKey]ownerKey) []*corev1.Pod {
+	key := workloadOwnerKey(kind, workload)
+	pods := append([]*corev1.Pod{}, podIndex[key]...)
+	if kind == "Deployment" {
+		for rsKey, depKey := range rsOwners {
+			if depKey == key {
+				pods = append(pods, podIndex[rsKey]...)
+			}
+		}
+	}
+	return pods
+}
+
+// rebindablePod strips the fields that would stop pod being recreated and
+// scheduled from scratch: its bound node, resource version and status. It
+// leaves Namespace/Name untouched (and UID, however it's set), which is what
+// schedulePods keys its in-flight sim.pending map on, so rebinding a pod
+// whose UID is empty is safe.
+func rebindablePod(pod *corev1.Pod) *corev1.Pod {
+	p := pod.DeepCopy()
+	p.Spec.NodeName = ""
+	p.ResourceVersion = ""
+	p.Status = corev1.PodStatus{}
+	return p
+}
+
+// ownerReplicaAutoMigrationPlugin is the shared implementation behind the
+// built-in Deployment/ReplicaSet/StatefulSet plugins: it counts pods that
+// have reported PodScheduled=False/Unschedulable for longer than threshold,
+// and reports them against the workload's desired replica count.
+type ownerReplicaAutoMigrationPlugin struct {
+	kind      string
+	threshold time.Duration
+	replicas  func(workload metav1.Object) (desired int32, ok bool)
+}
+
+func (p *ownerReplicaAutoMigrationPlugin) Name() string {
+	return p.kind
+}
+
+func (p *ownerReplicaAutoMigrationPlugin) EstimateUnschedulable(workload metav1.Object, pods []*corev1.Pod) (int, string) {
+	desired, ok := p.replicas(workload)
+	if !ok {
+		return 0, ""
+	}
+	stuck := countStuckUnschedulable(pods, p.threshold)
+	if stuck == 0 {
+		return 0, ""
+	}
+	return stuck, fmt.Sprintf("%d/%d desired replicas of %s %q have been unschedulable for over %s",
+		stuck, desired, p.kind, workload.GetName(), p.threshold)
+}
+
+// countStuckUnschedulable returns how many pods currently report
+// PodScheduled=False with reason Unschedulable, and have done so for at
+// least threshold.
+func countStuckUnschedulable(pods []*corev1.Pod, threshold time.Duration) int {
+	var n int
+	for _, pod := range pods {
+		for _, cond := range pod.Status.Conditions {
+			if cond.Type == corev1.PodScheduled && cond.Status == corev1.ConditionFalse && cond.Reason == corev1.PodReasonUnschedulable {
+				if time.Since(cond.LastTransitionTime.Time) >= threshold {
+					n++
+				}
+				break
+			}
+		}
+	}
+	return n
+}
+
+// NewDeploymentAutoMigrationPlugin returns the built-in AutoMigrationPlugin
+// for the "Deployment" kind.
+func NewDeploymentAutoMigrationPlugin(threshold time.Duration) AutoMigrationPlugin {
+	return &ownerReplicaAutoMigrationPlugin{
+		kind:      "Deployment",
+		threshold: threshold,
+		replicas: func(workload metav1.Object) (int32, bool) {
+			deploy, ok := workload.(*appsv1.Deployment)
+			if !ok {
+				return 0, false
+			}
+			if deploy.Spec.Replicas == nil {
+				return 1, true
+			}
+			return *deploy.Spec.Replicas, true
+		},
+	}
+}
+
+// NewReplicaSetAutoMigrationPlugin returns the built-in AutoMigrationPlugin
+// for the "ReplicaSet" kind.
+func NewReplicaSetAutoMigrationPlugin(threshold time.Duration) AutoMigrationPlugin {
+	return &ownerReplicaAutoMigrationPlugin{
+		kind:      "ReplicaSet",
+		threshold: threshold,
+		replicas: func(workload metav1.Object) (int32, bool) {
+			rs, ok := workload.(*appsv1.ReplicaSet)
+			if !ok {
+				return 0, false
+			}
+			if rs.Spec.Replicas == nil {
+				return 1, true
+			}
+			return *rs.Spec.Replicas, true
+		},
+	}
+}
+
+// NewStatefulSetAutoMigrationPlugin returns the built-in AutoMigrationPlugin
+// for the "StatefulSet" kind.
+func NewStatefulSetAutoMigrationPlugin(threshold time.Duration) AutoMigrationPlugin {
+	return &ownerReplicaAutoMigrationPlugin{
+		kind:      "StatefulSet",
+		threshold: threshold,
+		replicas: func(workload metav1.Object) (int32, bool) {
+			sts, ok := workload.(*appsv1.StatefulSet)
+			if !ok {
+				return 0, false
+			}
+			if sts.Spec.Replicas == nil {
+				return 1, true
+			}
+			return *sts.Spec.Replicas, true
+		},
+	}
+}
@@ -0,0 +1,106 @@
+package simulator
+
+import (
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func podOwnedBy(namespace, name, ownerKind, ownerName string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: namespace,
+			Name:      name,
+			OwnerReferences: []metav1.OwnerReference{
+				{Kind: ownerKind, Name: ownerName},
+			},
+		},
+	}
+}
+
+func TestWorkloadPodsWalksReplicaSetIndirection(t *testing.T) {
+	deploy := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "web"}}
+	rs := &appsv1.ReplicaSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:       "ns",
+			Name:            "web-abc123",
+			OwnerReferences: []metav1.OwnerReference{{Kind: "Deployment", Name: "web"}},
+		},
+	}
+	pods := []*corev1.Pod{
+		podOwnedBy("ns", "web-abc123-1", "ReplicaSet", "web-abc123"),
+		podOwnedBy("ns", "web-abc123-2", "ReplicaSet", "web-abc123"),
+	}
+
+	podIndex := buildPodOwnerIndex(pods)
+	rsOwners := replicaSetOwners([]*appsv1.ReplicaSet{rs})
+
+	got := workloadPods("Deployment", deploy, podIndex, rsOwners)
+	if len(got) != 2 {
+		t.Fatalf("expected 2 pods attributed to the Deployment via its ReplicaSet, got %d", len(got))
+	}
+}
+
+func TestWorkloadPodsDoesNotCollideOnEmptyUID(t *testing.T) {
+	// Deployments parsed from a custom-cluster YAML fixture typically have no
+	// metadata.uid set, so resolution must not key solely on UID or every
+	// workload would share the same UID("") bucket.
+	deployA := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "a"}}
+	deployB := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "b"}}
+	pods := []*corev1.Pod{
+		podOwnedBy("ns", "a-1", "Deployment", "a"),
+		podOwnedBy("ns", "b-1", "Deployment", "b"),
+		podOwnedBy("ns", "b-2", "Deployment", "b"),
+	}
+
+	podIndex := buildPodOwnerIndex(pods)
+	rsOwners := replicaSetOwners(nil)
+
+	gotA := workloadPods("Deployment", deployA, podIndex, rsOwners)
+	gotB := workloadPods("Deployment", deployB, podIndex, rsOwners)
+	if len(gotA) != 1 {
+		t.Fatalf("expected 1 pod for deployment a, got %d", len(gotA))
+	}
+	if len(gotB) != 2 {
+		t.Fatalf("expected 2 pods for deployment b, got %d", len(gotB))
+	}
+}
+
+func TestCountStuckUnschedulable(t *testing.T) {
+	now := time.Now()
+	stuckLongEnough := corev1.PodStatus{
+		Conditions: []corev1.PodCondition{{
+			Type:               corev1.PodScheduled,
+			Status:             corev1.ConditionFalse,
+			Reason:             corev1.PodReasonUnschedulable,
+			LastTransitionTime: metav1.NewTime(now.Add(-10 * time.Minute)),
+		}},
+	}
+	stuckRecently := corev1.PodStatus{
+		Conditions: []corev1.PodCondition{{
+			Type:               corev1.PodScheduled,
+			Status:             corev1.ConditionFalse,
+			Reason:             corev1.PodReasonUnschedulable,
+			LastTransitionTime: metav1.NewTime(now.Add(-time.Minute)),
+		}},
+	}
+	scheduled := corev1.PodStatus{
+		Conditions: []corev1.PodCondition{{
+			Type:   corev1.PodScheduled,
+			Status: corev1.ConditionTrue,
+		}},
+	}
+
+	pods := []*corev1.Pod{
+		{Status: stuckLongEnough},
+		{Status: stuckRecently},
+		{Status: scheduled},
+	}
+
+	if got := countStuckUnschedulable(pods, 5*time.Minute); got != 1 {
+		t.Fatalf("expected 1 pod stuck past the threshold, got %d", got)
+	}
+}
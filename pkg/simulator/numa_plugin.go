@@ -0,0 +1,118 @@
+package simulator
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	resourcehelper "k8s.io/kubectl/pkg/util/resource"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+)
+
+// NumaZoneFitPluginName is the name NumaZoneFitPlugin registers itself
+// under in the scheduler framework.
+const NumaZoneFitPluginName = "NumaZoneFit"
+
+// numaGpuResourceName is the device-plugin resource name for a whole,
+// exclusively-held GPU, used here only to size a pod's GPU request for the
+// capacity check in numaZoneFitScore (IsNumaAligned takes no GPU count).
+const numaGpuResourceName corev1.ResourceName = "nvidia.com/gpu"
+
+// NumaZoneFitPlugin is a FilterPlugin/ScorePlugin that enforces the NUMA
+// topology hints computed in topology.go: pods that set
+// AnnoPodTopologyPolicy to TopologyPolicySingleNumaNode or
+// TopologyPolicyRestricted are rejected from nodes whose topology hint can't
+// satisfy their CPU, memory and GPU count from a single NUMA zone, and among
+// the nodes that can, tighter-fitting zones score higher. Both policies are
+// enforced identically here: this simulator doesn't model per-resource
+// topology hint providers, so there's no basis to be less strict for
+// restricted than for single-numa-node. TopologyPolicyBestEffort pods are
+// scored but never rejected. It is a no-op Filter/Score for every other pod,
+// so enabling it is safe for simulations that don't use topology hints at
+// all.
+type NumaZoneFitPlugin struct {
+	handle framework.Handle
+}
+
+// requiresNumaAlignment reports whether policy is strict enough that
+// NumaZoneFitPlugin.Filter should reject a misaligned placement outright,
+// rather than merely scoring it lower.
+func requiresNumaAlignment(policy string) bool {
+	return policy == TopologyPolicySingleNumaNode || policy == TopologyPolicyRestricted
+}
+
+// NewNumaZoneFitPlugin is a frameworkruntime.PluginFactory for
+// NumaZoneFitPlugin.
+func NewNumaZoneFitPlugin(handle framework.Handle) (framework.Plugin, error) {
+	return &NumaZoneFitPlugin{handle: handle}, nil
+}
+
+func (p *NumaZoneFitPlugin) Name() string {
+	return NumaZoneFitPluginName
+}
+
+// podNumaRequest returns the CPU, memory and whole-GPU count a pod requests,
+// for matching against a node's NUMA zones.
+func podNumaRequest(pod *corev1.Pod) (cpuMilli, memBytes, gpuCount int64) {
+	reqs, _ := resourcehelper.PodRequestsAndLimits(pod)
+	return reqs[corev1.ResourceCPU].MilliValue(), reqs[corev1.ResourceMemory].Value(), reqs[numaGpuResourceName].Value()
+}
+
+func (p *NumaZoneFitPlugin) Filter(_ context.Context, _ *framework.CycleState, pod *corev1.Pod, nodeInfo *framework.NodeInfo) *framework.Status {
+	if !requiresNumaAlignment(PodTopologyPolicy(pod)) {
+		return nil
+	}
+	node := nodeInfo.Node()
+	if node == nil {
+		return framework.NewStatus(framework.Error, "node not found")
+	}
+	hint, err := ParseTopologyHint(node)
+	if err != nil {
+		return framework.AsStatus(err)
+	}
+	if hint == nil {
+		return framework.NewStatus(framework.UnschedulableAndUnresolvable,
+			fmt.Sprintf("node %s has no NUMA topology hint", node.Name))
+	}
+
+	cpuMilli, memBytes, gpuCount := podNumaRequest(pod)
+	gpuIndex := -1
+	if gpuCount == 0 {
+		if aligned, reason := IsNumaAligned(hint, cpuMilli, memBytes, gpuIndex); !aligned {
+			return framework.NewStatus(framework.Unschedulable, reason)
+		}
+		return nil
+	}
+	// The specific device a GPU pod lands on is decided later by the GPU
+	// device-selection plugin, so Filter can only check that some zone has
+	// enough declared GPU indices, CPU and memory capacity for the request.
+	if numaZoneFitScore(hint, cpuMilli, memBytes, gpuCount) == 0 {
+		return framework.NewStatus(framework.Unschedulable,
+			"no single NUMA zone can satisfy the pod's cpu, memory and gpu requests together")
+	}
+	return nil
+}
+
+func (p *NumaZoneFitPlugin) ScoreExtensions() framework.ScoreExtensions {
+	return nil
+}
+
+func (p *NumaZoneFitPlugin) Score(_ context.Context, _ *framework.CycleState, pod *corev1.Pod, nodeName string) (int64, *framework.Status) {
+	if PodTopologyPolicy(pod) == "" {
+		return 0, nil
+	}
+	nodeInfo, err := p.handle.SnapshotSharedLister().NodeInfos().Get(nodeName)
+	if err != nil {
+		return 0, framework.AsStatus(err)
+	}
+	node := nodeInfo.Node()
+	if node == nil {
+		return 0, framework.NewStatus(framework.Error, "node not found")
+	}
+	hint, err := ParseTopologyHint(node)
+	if err != nil {
+		return 0, framework.AsStatus(err)
+	}
+	cpuMilli, memBytes, gpuCount := podNumaRequest(pod)
+	return numaZoneFitScore(hint, cpuMilli, memBytes, gpuCount), nil
+}
@@ -0,0 +1,97 @@
+package simulator
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+)
+
+func numaTestNode(hint *TopologyHint) *corev1.Node {
+	raw, err := json.Marshal(hint)
+	if err != nil {
+		panic(err)
+	}
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "node-1",
+			Annotations: map[string]string{AnnoNodeTopologyHint: string(raw)},
+		},
+	}
+}
+
+func numaTestPod(policy string, cpu, memory string) *corev1.Pod {
+	annotations := map[string]string{}
+	if policy != "" {
+		annotations[AnnoPodTopologyPolicy] = policy
+	}
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "pod-1", Annotations: annotations},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{
+				Resources: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{
+						corev1.ResourceCPU:    resource.MustParse(cpu),
+						corev1.ResourceMemory: resource.MustParse(memory),
+					},
+				},
+			}},
+		},
+	}
+}
+
+func misalignedHint() *TopologyHint {
+	return &TopologyHint{
+		Zones: []NumaZone{
+			{ID: 0, CPUIDs: []int{0, 1}, MemoryBytes: 1 << 30},
+			{ID: 1, CPUIDs: []int{2, 3}, MemoryBytes: 1 << 30},
+		},
+	}
+}
+
+func TestNumaZoneFitFilterRejectsMisalignedRestrictedPod(t *testing.T) {
+	plugin := &NumaZoneFitPlugin{}
+	nodeInfo := framework.NewNodeInfo()
+	nodeInfo.SetNode(numaTestNode(misalignedHint()))
+
+	// A 3-CPU request can't come from either single 2-CPU zone, so a
+	// "restricted" pod (like "single-numa-node") must be rejected rather than
+	// silently let through.
+	pod := numaTestPod(TopologyPolicyRestricted, "3", "512Mi")
+
+	status := plugin.Filter(context.Background(), nil, pod, nodeInfo)
+	if status.IsSuccess() {
+		t.Fatal("expected Filter to reject a misaligned restricted pod, got success")
+	}
+}
+
+func TestNumaZoneFitFilterAllowsMisalignedBestEffortPod(t *testing.T) {
+	plugin := &NumaZoneFitPlugin{}
+	nodeInfo := framework.NewNodeInfo()
+	nodeInfo.SetNode(numaTestNode(misalignedHint()))
+
+	pod := numaTestPod(TopologyPolicyBestEffort, "3", "512Mi")
+
+	status := plugin.Filter(context.Background(), nil, pod, nodeInfo)
+	if !status.IsSuccess() {
+		t.Fatalf("expected Filter to allow a misaligned best-effort pod, got %v", status)
+	}
+}
+
+func TestRequiresNumaAlignment(t *testing.T) {
+	cases := map[string]bool{
+		TopologyPolicySingleNumaNode: true,
+		TopologyPolicyRestricted:     true,
+		TopologyPolicyBestEffort:     false,
+		"":                           false,
+	}
+	for policy, want := range cases {
+		if got := requiresNumaAlignment(policy); got != want {
+			t.Errorf("requiresNumaAlignment(%q) = %v, want %v", policy, got, want)
+		}
+	}
+}
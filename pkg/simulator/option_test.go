@@ -0,0 +1,40 @@
+package simulator
+
+import (
+	"testing"
+
+	"github.com/go-logr/logr"
+)
+
+func TestWithLoggerIgnoresZeroValue(t *testing.T) {
+	options := defaultSimulatorOptions
+	WithLogger(logr.Logger{})(&options)
+	if options.logger != defaultSimulatorOptions.logger {
+		t.Fatalf("expected a zero-value logr.Logger to be ignored, got %+v", options.logger)
+	}
+}
+
+func TestWithLoggerSetsNonZeroValue(t *testing.T) {
+	options := defaultSimulatorOptions
+	logger := logr.Discard()
+	WithLogger(logger)(&options)
+	if options.logger.GetSink() != logger.GetSink() {
+		t.Fatalf("expected the logger to be applied")
+	}
+}
+
+func TestWithParallelismIgnoresNonPositive(t *testing.T) {
+	options := defaultSimulatorOptions
+	WithParallelism(0)(&options)
+	if options.parallelism != defaultSimulatorOptions.parallelism {
+		t.Fatalf("expected parallelism 0 to be ignored, got %d", options.parallelism)
+	}
+	WithParallelism(-1)(&options)
+	if options.parallelism != defaultSimulatorOptions.parallelism {
+		t.Fatalf("expected negative parallelism to be ignored, got %d", options.parallelism)
+	}
+	WithParallelism(4)(&options)
+	if options.parallelism != 4 {
+		t.Fatalf("expected parallelism to be set to 4, got %d", options.parallelism)
+	}
+}
@@ -0,0 +1,84 @@
+package simulator
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	fakeclientset "k8s.io/client-go/kubernetes/fake"
+)
+
+// TestSchedulePodsHandlesConcurrentPodsWithoutUID exercises schedulePods with
+// several pods in flight at once, all sharing an empty UID — the normal case
+// for pods built from custom-cluster YAML or recreated by automigration's
+// rebindablePod, since fakeclientset's ObjectTracker never assigns one.
+// sim.pending used to be keyed by pod.UID, so concurrent goroutines would
+// overwrite each other's UID("") entry and leave every other goroutine
+// blocked forever on <-ch once a different pod's result was delivered and
+// its entry deleted. A real scheduler isn't wired up here; a watch on the
+// fake client stands in for it, reporting every created pod as scheduled as
+// soon as it is observed.
+func TestSchedulePodsHandlesConcurrentPodsWithoutUID(t *testing.T) {
+	fakeclient := fakeclientset.NewSimpleClientset()
+	ctx := context.Background()
+
+	sim := &Simulator{
+		fakeclient:   fakeclient,
+		pending:      make(map[types.NamespacedName]chan schedResult),
+		parallelism:  4,
+		ctx:          ctx,
+		disablePTerm: true,
+	}
+
+	watcher, err := fakeclient.CoreV1().Pods(corev1.NamespaceAll).Watch(ctx, metav1.ListOptions{})
+	if err != nil {
+		t.Fatalf("failed to watch pods: %v", err)
+	}
+	defer watcher.Stop()
+	go func() {
+		for event := range watcher.ResultChan() {
+			pod, ok := event.Object.(*corev1.Pod)
+			if !ok {
+				continue
+			}
+			scheduled := pod.DeepCopy()
+			scheduled.Spec.NodeName = "node-1"
+			sim.update(scheduled)
+		}
+	}()
+
+	var pods []*corev1.Pod
+	for i := 0; i < 20; i++ {
+		pods = append(pods, &corev1.Pod{
+			// UID deliberately left unset, matching pods built from
+			// custom-cluster YAML fixtures in this codebase.
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: fmt.Sprintf("pod-%d", i)},
+		})
+	}
+
+	type schedulePodsResult struct {
+		failedPods []UnscheduledPod
+		err        error
+	}
+	done := make(chan schedulePodsResult, 1)
+	go func() {
+		failedPods, err := sim.schedulePods(ctx, pods)
+		done <- schedulePodsResult{failedPods: failedPods, err: err}
+	}()
+
+	select {
+	case res := <-done:
+		if res.err != nil {
+			t.Fatalf("schedulePods returned error: %v", res.err)
+		}
+		if len(res.failedPods) != 0 {
+			t.Fatalf("expected all pods to be scheduled, got %d failures: %+v", len(res.failedPods), res.failedPods)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("schedulePods deadlocked — likely a sim.pending key collision across UID-less pods")
+	}
+}
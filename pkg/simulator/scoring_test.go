@@ -0,0 +1,57 @@
+package simulator
+
+import (
+	"testing"
+
+	kubeschedulerconfig "k8s.io/kubernetes/pkg/scheduler/apis/config"
+)
+
+func TestNodeResourcesFitScoringStrategy(t *testing.T) {
+	cases := map[string]kubeschedulerconfig.ScoringStrategyType{
+		ScoringPolicyBinpack:        kubeschedulerconfig.MostAllocated,
+		ScoringPolicyMostAllocated:  kubeschedulerconfig.MostAllocated,
+		ScoringPolicySpread:         kubeschedulerconfig.LeastAllocated,
+		ScoringPolicyLeastAllocated: kubeschedulerconfig.LeastAllocated,
+		"":                          kubeschedulerconfig.LeastAllocated,
+	}
+	for policy, want := range cases {
+		if got := nodeResourcesFitScoringStrategy(policy); got != want {
+			t.Errorf("nodeResourcesFitScoringStrategy(%q) = %v, want %v", policy, got, want)
+		}
+	}
+}
+
+func TestApplyScoringPolicyCreatesPluginConfig(t *testing.T) {
+	profiles := []kubeschedulerconfig.KubeSchedulerProfile{{SchedulerName: "default-scheduler"}}
+	applyScoringPolicy(profiles, ScoringPolicyBinpack)
+
+	if len(profiles[0].PluginConfig) != 1 {
+		t.Fatalf("expected one PluginConfig entry, got %d", len(profiles[0].PluginConfig))
+	}
+	args, ok := profiles[0].PluginConfig[0].Args.(*kubeschedulerconfig.NodeResourcesFitArgs)
+	if !ok {
+		t.Fatalf("expected *NodeResourcesFitArgs, got %T", profiles[0].PluginConfig[0].Args)
+	}
+	if args.ScoringStrategy == nil || args.ScoringStrategy.Type != kubeschedulerconfig.MostAllocated {
+		t.Fatalf("expected MostAllocated scoring strategy, got %+v", args.ScoringStrategy)
+	}
+}
+
+func TestApplyScoringPolicyReusesExistingPluginConfig(t *testing.T) {
+	existing := &kubeschedulerconfig.NodeResourcesFitArgs{}
+	profiles := []kubeschedulerconfig.KubeSchedulerProfile{{
+		PluginConfig: []kubeschedulerconfig.PluginConfig{{
+			Name: nodeResourcesFitPluginName,
+			Args: existing,
+		}},
+	}}
+
+	applyScoringPolicy(profiles, ScoringPolicySpread)
+
+	if len(profiles[0].PluginConfig) != 1 {
+		t.Fatalf("expected the existing PluginConfig entry to be reused, got %d entries", len(profiles[0].PluginConfig))
+	}
+	if existing.ScoringStrategy == nil || existing.ScoringStrategy.Type != kubeschedulerconfig.LeastAllocated {
+		t.Fatalf("expected existing args to be updated in place, got %+v", existing.ScoringStrategy)
+	}
+}
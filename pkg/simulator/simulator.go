@@ -3,18 +3,21 @@ package simulator
 import (
 	"context"
 	"fmt"
-	"strings"
+	"sync"
 	"time"
 
+	"github.com/go-logr/logr"
 	"github.com/pterm/pterm"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/informers"
 	kubeinformers "k8s.io/client-go/informers"
 	externalclientset "k8s.io/client-go/kubernetes"
 	fakeclientset "k8s.io/client-go/kubernetes/fake"
 	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
 	"k8s.io/kubernetes/pkg/scheduler"
 	kubeschedulerconfig "k8s.io/kubernetes/pkg/scheduler/apis/config"
 	"k8s.io/kubernetes/pkg/scheduler/framework"
@@ -22,7 +25,6 @@ import (
 	utiltrace "k8s.io/utils/trace"
 
 	simonplugin "github.com/alibaba/open-simulator/pkg/simulator/plugin"
-	"github.com/alibaba/open-simulator/pkg/test"
 	simontype "github.com/alibaba/open-simulator/pkg/type"
 	"github.com/alibaba/open-simulator/pkg/utils"
 	"k8s.io/client-go/tools/events"
@@ -38,8 +40,18 @@ type Simulator struct {
 	// scheduler
 	scheduler *scheduler.Scheduler
 
-	// stopCh
-	simulatorStop chan struct{}
+	// pending tracks in-flight pods by namespace/name. schedulePods registers
+	// a channel before creating each pod; the pod informer's update handler
+	// looks it up and delivers the terminal schedResult once the pod binds or
+	// is reported unschedulable. Keyed by types.NamespacedName rather than
+	// UID: pods built for simulation (from custom-cluster YAML, or
+	// re-created by automigration's rebindablePod) routinely have no
+	// metadata.uid set, and fakeclientset's ObjectTracker doesn't assign one
+	// on Create, so UID("") would collide across concurrently in-flight pods.
+	pending   map[types.NamespacedName]chan schedResult
+	pendingMu sync.Mutex
+	// parallelism bounds how many pods schedulePods has in flight at once.
+	parallelism int
 
 	// context
 	ctx                   context.Context
@@ -49,28 +61,50 @@ type Simulator struct {
 
 	eventBroadcaster events.EventBroadcasterAdapter
 
-	disablePTerm    bool
-	patchPodFuncMap PatchPodsFuncMap
+	disablePTerm         bool
+	patchPodFuncMap      PatchPodsFuncMap
+	autoMigrationPlugins AutoMigrationPluginMap
 
-	status status
+	// rebuildCluster recreates the informers and scheduler around a new
+	// fakeclient. Restore calls it to invalidate every cache after swapping
+	// in the restored snapshot's data.
+	rebuildCluster func(fakeClient externalclientset.Interface) error
 }
 
-// status captures reason why one pod fails to be scheduled
-type status struct {
-	stopReason string
+// schedResult is the terminal outcome of scheduling one pod, delivered on its
+// channel in Simulator.pending.
+type schedResult struct {
+	scheduled bool
+	reason    string
 }
 
 type PatchPodFunc = func(pods []*corev1.Pod, client externalclientset.Interface) error
 
 type PatchPodsFuncMap map[string]PatchPodFunc
 
+// Node- and GPU-selection scoring policies. ScoringPolicyLeastAllocated is
+// the default and matches the previous UseGreed=false behavior; binpack
+// matches UseGreed=true.
+const (
+	ScoringPolicyBinpack        = "binpack"
+	ScoringPolicySpread         = "spread"
+	ScoringPolicyLeastAllocated = "least-allocated"
+	ScoringPolicyMostAllocated  = "most-allocated"
+)
+
 type simulatorOptions struct {
-	kubeconfig         string
-	schedulerConfig    string
-	scheduleConfigOpts []ScheduleConfigOption
-	disablePTerm       bool
-	extraRegistry      frameworkruntime.Registry
-	patchPodFuncMap    PatchPodsFuncMap
+	kubeconfig            string
+	schedulerConfig       string
+	scheduleConfigOpts    []ScheduleConfigOption
+	disablePTerm          bool
+	extraRegistry         frameworkruntime.Registry
+	patchPodFuncMap       PatchPodsFuncMap
+	scoringPolicy         string
+	gpuScoringPolicy      string
+	logger                logr.Logger
+	parallelism           int
+	autoMigrationPlugins  AutoMigrationPluginMap
+	sharedInformerFactory informers.SharedInformerFactory
 }
 
 // Option configures a Simulator
@@ -78,12 +112,18 @@ type Option func(*simulatorOptions)
 type ScheduleConfigOption func(kubeschedulerconfig.KubeSchedulerConfiguration)
 
 var defaultSimulatorOptions = simulatorOptions{
-	kubeconfig:         "",
-	schedulerConfig:    "",
-	disablePTerm:       false,
-	scheduleConfigOpts: []ScheduleConfigOption{},
-	extraRegistry:      make(map[string]frameworkruntime.PluginFactory),
-	patchPodFuncMap:    make(map[string]PatchPodFunc),
+	kubeconfig:            "",
+	schedulerConfig:       "",
+	disablePTerm:          false,
+	scheduleConfigOpts:    []ScheduleConfigOption{},
+	extraRegistry:         make(map[string]frameworkruntime.PluginFactory),
+	patchPodFuncMap:       make(map[string]PatchPodFunc),
+	scoringPolicy:         ScoringPolicyLeastAllocated,
+	gpuScoringPolicy:      ScoringPolicyLeastAllocated,
+	logger:                klog.Background(),
+	parallelism:           1,
+	autoMigrationPlugins:  AutoMigrationPluginMap{},
+	sharedInformerFactory: nil,
 }
 
 // NewSimulator generates all components that will be needed to simulate scheduling and returns a complete simulator
@@ -97,114 +137,153 @@ func NewSimulator(ctx context.Context, opts ...Option) (*Simulator, error) {
 
 	// Step 1: get scheduler CompletedConfig and set the list of scheduler bind plugins to Simon.
 	kubeSchedulerConfig := GetSchedulerConfig(options.scheduleConfigOpts...)
+	applyScoringPolicy(kubeSchedulerConfig.ComponentConfig.Profiles, options.scoringPolicy)
+	enableNumaZoneFitPlugin(kubeSchedulerConfig.ComponentConfig.Profiles)
 
 	// Step 2: create client
 	fakeClient := fakeclientset.NewSimpleClientset()
 	kubeSchedulerConfig.Client = fakeClient
 
 	// Step 3: Create the simulator
+	ctx = klog.NewContext(ctx, options.logger)
 	ctx, cancel := context.WithCancel(ctx)
 	scheduleOneCtx, scheduleOneCancel := context.WithCancel(ctx)
+
+	autoMigrationPlugins := AutoMigrationPluginMap{
+		"Deployment":  NewDeploymentAutoMigrationPlugin(DefaultUnschedulableThreshold),
+		"ReplicaSet":  NewReplicaSetAutoMigrationPlugin(DefaultUnschedulableThreshold),
+		"StatefulSet": NewStatefulSetAutoMigrationPlugin(DefaultUnschedulableThreshold),
+	}
+	for kind, plugin := range options.autoMigrationPlugins {
+		autoMigrationPlugins[kind] = plugin
+	}
+
 	sim := &Simulator{
 		fakeclient:            fakeClient,
-		simulatorStop:         make(chan struct{}),
+		pending:               make(map[types.NamespacedName]chan schedResult),
+		parallelism:           options.parallelism,
 		ctx:                   ctx,
 		cancelFunc:            cancel,
 		scheduleOneCtx:        scheduleOneCtx,
 		scheduleOneCancelFunc: scheduleOneCancel,
 		disablePTerm:          options.disablePTerm,
 		patchPodFuncMap:       options.patchPodFuncMap,
+		autoMigrationPlugins:  autoMigrationPlugins,
 		eventBroadcaster:      kubeSchedulerConfig.EventBroadcaster,
 	}
 
-	// Step 4: create informer
-	kubeInformerFactory := kubeinformers.NewSharedInformerFactory(sim.fakeclient, 0)
-	scInformer := kubeInformerFactory.Storage().V1().StorageClasses().Informer()
-	csiNodeInformer := kubeInformerFactory.Storage().V1().CSINodes().Informer()
-	cmInformer := kubeInformerFactory.Core().V1().ConfigMaps().Informer()
-	svcInformer := kubeInformerFactory.Core().V1().Services().Informer()
-	podInformer := kubeInformerFactory.Core().V1().Pods().Informer()
-	pdbInformer := kubeInformerFactory.Policy().V1beta1().PodDisruptionBudgets().Informer()
-	pvcInformer := kubeInformerFactory.Core().V1().PersistentVolumeClaims().Informer()
-	pvInformer := kubeInformerFactory.Core().V1().PersistentVolumes().Informer()
-	rcInformer := kubeInformerFactory.Core().V1().ReplicationControllers().Informer()
-	rsInformer := kubeInformerFactory.Apps().V1().ReplicaSets().Informer()
-	stsInformer := kubeInformerFactory.Apps().V1().StatefulSets().Informer()
-	nodeInformer := kubeInformerFactory.Core().V1().Nodes().Informer()
-	dsInformer := kubeInformerFactory.Apps().V1().DaemonSets().Informer()
-	deployInformer := kubeInformerFactory.Apps().V1().Deployments().Informer()
-
-	// Step 5: add event handler for pods
-	kubeInformerFactory.Core().V1().Pods().Informer().AddEventHandler(
-		cache.FilteringResourceEventHandler{
-			FilterFunc: func(obj interface{}) bool {
-				if pod, ok := obj.(*corev1.Pod); ok && pod.Spec.SchedulerName == simontype.DefaultSchedulerName {
-					return true
-				}
-				return false
-			},
-			Handler: cache.ResourceEventHandlerFuncs{
-				// AddFunc: func(obj interface{}) {
-				// 	if pod, ok := obj.(*corev1.Pod); ok {
-				// 		fmt.Printf("test add pod %s/%s\n", pod.Namespace, pod.Name)
-				// 	}
-				// },
-				UpdateFunc: func(oldObj, newObj interface{}) {
-					if pod, ok := newObj.(*corev1.Pod); ok {
-						// fmt.Printf("test update pod %s/%s\n", pod.Namespace, pod.Name)
-						sim.update(pod)
-					}
-				},
-			},
-		},
-	)
-	sim.informerFactory = kubeInformerFactory
-
-	// Step 6: start informer
-	sim.informerFactory.Start(ctx.Done())
-	cache.WaitForCacheSync(ctx.Done(),
-		scInformer.HasSynced,
-		csiNodeInformer.HasSynced,
-		cmInformer.HasSynced,
-		svcInformer.HasSynced,
-		podInformer.HasSynced,
-		pdbInformer.HasSynced,
-		pvcInformer.HasSynced,
-		pvInformer.HasSynced,
-		rcInformer.HasSynced,
-		rsInformer.HasSynced,
-		stsInformer.HasSynced,
-		nodeInformer.HasSynced,
-		dsInformer.HasSynced,
-		deployInformer.HasSynced,
-	)
-
-	// Step 7: create scheduler for sim
+	// rebuildCluster (re)builds everything downstream of the fakeclient:
+	// informers, the pod event handler that feeds sim.update, and the
+	// scheduler. NewSimulator runs it once below; Restore re-runs it against
+	// a freshly populated fakeclient so no informer or scheduler cache can
+	// see objects from before the restore.
 	bindRegistry := frameworkruntime.Registry{
 		simontype.SimonPluginName: func(configuration runtime.Object, f framework.Handle) (framework.Plugin, error) {
 			return simonplugin.NewSimonPlugin(sim.fakeclient, configuration, f)
 		},
+		NumaZoneFitPluginName: func(_ runtime.Object, f framework.Handle) (framework.Plugin, error) {
+			return NewNumaZoneFitPlugin(f)
+		},
 	}
 	for name, plugin := range options.extraRegistry {
 		bindRegistry[name] = plugin
 	}
 
-	sim.scheduler, err = scheduler.New(
-		sim.fakeclient,
-		sim.informerFactory,
-		nil,
-		GetRecorderFactory(kubeSchedulerConfig),
-		sim.ctx.Done(),
-		scheduler.WithProfiles(kubeSchedulerConfig.ComponentConfig.Profiles...),
-		scheduler.WithPercentageOfNodesToScore(kubeSchedulerConfig.ComponentConfig.PercentageOfNodesToScore),
-		scheduler.WithFrameworkOutOfTreeRegistry(bindRegistry),
-		scheduler.WithPodMaxBackoffSeconds(kubeSchedulerConfig.ComponentConfig.PodMaxBackoffSeconds),
-		scheduler.WithPodInitialBackoffSeconds(kubeSchedulerConfig.ComponentConfig.PodInitialBackoffSeconds),
-		scheduler.WithExtenders(kubeSchedulerConfig.ComponentConfig.Extenders...),
-	)
-	if err != nil {
+	// sharedInformerFactory is consumed on the first rebuildCluster call only:
+	// a caller-supplied factory is bound to the fakeclient it was built
+	// against, so it can't be reused once Restore swaps in a new one.
+	sharedInformerFactory := options.sharedInformerFactory
+	rebuildCluster := func(fakeClient externalclientset.Interface) error {
+		sim.fakeclient = fakeClient
+		kubeSchedulerConfig.Client = fakeClient
+
+		// Step 4: create informer, reusing a caller-supplied factory (e.g. one
+		// already running in a host controller) when given one, so embedders
+		// don't pay for a second full cache.
+		kubeInformerFactory := sharedInformerFactory
+		if kubeInformerFactory == nil {
+			kubeInformerFactory = kubeinformers.NewSharedInformerFactory(sim.fakeclient, 0)
+		}
+		sharedInformerFactory = nil
+		scInformer := kubeInformerFactory.Storage().V1().StorageClasses().Informer()
+		csiNodeInformer := kubeInformerFactory.Storage().V1().CSINodes().Informer()
+		cmInformer := kubeInformerFactory.Core().V1().ConfigMaps().Informer()
+		svcInformer := kubeInformerFactory.Core().V1().Services().Informer()
+		podInformer := kubeInformerFactory.Core().V1().Pods().Informer()
+		pdbInformer := kubeInformerFactory.Policy().V1beta1().PodDisruptionBudgets().Informer()
+		pvcInformer := kubeInformerFactory.Core().V1().PersistentVolumeClaims().Informer()
+		pvInformer := kubeInformerFactory.Core().V1().PersistentVolumes().Informer()
+		rcInformer := kubeInformerFactory.Core().V1().ReplicationControllers().Informer()
+		rsInformer := kubeInformerFactory.Apps().V1().ReplicaSets().Informer()
+		stsInformer := kubeInformerFactory.Apps().V1().StatefulSets().Informer()
+		nodeInformer := kubeInformerFactory.Core().V1().Nodes().Informer()
+		dsInformer := kubeInformerFactory.Apps().V1().DaemonSets().Informer()
+		deployInformer := kubeInformerFactory.Apps().V1().Deployments().Informer()
+
+		// Step 5: add event handler for pods
+		kubeInformerFactory.Core().V1().Pods().Informer().AddEventHandler(
+			cache.FilteringResourceEventHandler{
+				FilterFunc: func(obj interface{}) bool {
+					if pod, ok := obj.(*corev1.Pod); ok && pod.Spec.SchedulerName == simontype.DefaultSchedulerName {
+						return true
+					}
+					return false
+				},
+				Handler: cache.ResourceEventHandlerFuncs{
+					UpdateFunc: func(oldObj, newObj interface{}) {
+						if pod, ok := newObj.(*corev1.Pod); ok {
+							sim.update(pod)
+						}
+					},
+				},
+			},
+		)
+		sim.informerFactory = kubeInformerFactory
+
+		// Step 6: start informer
+		sim.informerFactory.Start(sim.ctx.Done())
+		cache.WaitForCacheSync(sim.ctx.Done(),
+			scInformer.HasSynced,
+			csiNodeInformer.HasSynced,
+			cmInformer.HasSynced,
+			svcInformer.HasSynced,
+			podInformer.HasSynced,
+			pdbInformer.HasSynced,
+			pvcInformer.HasSynced,
+			pvInformer.HasSynced,
+			rcInformer.HasSynced,
+			rsInformer.HasSynced,
+			stsInformer.HasSynced,
+			nodeInformer.HasSynced,
+			dsInformer.HasSynced,
+			deployInformer.HasSynced,
+		)
+
+		// Step 7: create scheduler for sim
+		newScheduler, err := scheduler.New(
+			sim.fakeclient,
+			sim.informerFactory,
+			nil,
+			GetRecorderFactory(kubeSchedulerConfig),
+			sim.ctx.Done(),
+			scheduler.WithProfiles(kubeSchedulerConfig.ComponentConfig.Profiles...),
+			scheduler.WithPercentageOfNodesToScore(kubeSchedulerConfig.ComponentConfig.PercentageOfNodesToScore),
+			scheduler.WithFrameworkOutOfTreeRegistry(bindRegistry),
+			scheduler.WithPodMaxBackoffSeconds(kubeSchedulerConfig.ComponentConfig.PodMaxBackoffSeconds),
+			scheduler.WithPodInitialBackoffSeconds(kubeSchedulerConfig.ComponentConfig.PodInitialBackoffSeconds),
+			scheduler.WithExtenders(kubeSchedulerConfig.ComponentConfig.Extenders...),
+		)
+		if err != nil {
+			return err
+		}
+		sim.scheduler = newScheduler
+		return nil
+	}
+
+	if err = rebuildCluster(fakeClient); err != nil {
 		return nil, err
 	}
+	sim.rebuildCluster = rebuildCluster
 
 	return sim, nil
 }
@@ -293,9 +372,12 @@ func (sim *Simulator) runScheduler() {
 	go sim.scheduler.Run(sim.scheduleOneCtx)
 }
 
-// Run starts to schedule pods
+// schedulePods starts to schedule pods. Up to sim.parallelism pods are in flight at
+// once: each is created, registered in sim.pending under its namespace/name,
+// and waits on its own channel for the pod informer's update handler to
+// report a terminal outcome. The returned UnscheduledPods preserve the order
+// of pods.
 func (sim *Simulator) schedulePods(ctx context.Context, pods []*corev1.Pod) ([]UnscheduledPod, error) {
-	var failedPods []UnscheduledPod
 	var progressBar *pterm.ProgressbarPrinter
 	if !sim.disablePTerm {
 		progressBar, _ = pterm.DefaultProgressbar.WithTotal(len(pods)).Start()
@@ -303,33 +385,78 @@ func (sim *Simulator) schedulePods(ctx context.Context, pods []*corev1.Pod) ([]U
 			_, _ = progressBar.Stop()
 		}()
 	}
-	for _, pod := range pods {
-		if !sim.disablePTerm {
-			// Update the title of the progressbar.
-			progressBar.UpdateTitle(fmt.Sprintf("%s/%s", pod.Namespace, pod.Name))
-		}
-		if _, err := sim.fakeclient.CoreV1().Pods(pod.Namespace).Create(ctx, pod, metav1.CreateOptions{}); err != nil {
-			return nil, fmt.Errorf("%s %s/%s: %s", simontype.CreatePodError, pod.Namespace, pod.Name, err.Error())
-		}
+	logger := klog.FromContext(ctx)
+
+	results := make([]schedResult, len(pods))
+	sem := make(chan struct{}, sim.parallelism)
+	var progressMu sync.Mutex
+	var errMu sync.Mutex
+	var firstErr error
+	var wg sync.WaitGroup
+
+	for n, pod := range pods {
+		n, pod := n, pod
+		sem <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			podLogger := logger.WithValues("pod", klog.KObj(pod), "namespace", pod.Namespace, "attempt", n)
+			podCtx := klog.NewContext(ctx, podLogger)
+
+			podKey := types.NamespacedName{Namespace: pod.Namespace, Name: pod.Name}
+			ch := make(chan schedResult, 1)
+			sim.pendingMu.Lock()
+			sim.pending[podKey] = ch
+			sim.pendingMu.Unlock()
+
+			if _, err := sim.fakeclient.CoreV1().Pods(pod.Namespace).Create(podCtx, pod, metav1.CreateOptions{}); err != nil {
+				sim.pendingMu.Lock()
+				delete(sim.pending, podKey)
+				sim.pendingMu.Unlock()
+				errMu.Lock()
+				if firstErr == nil {
+					firstErr = fmt.Errorf("%s %s/%s: %s", simontype.CreatePodError, pod.Namespace, pod.Name, err.Error())
+				}
+				errMu.Unlock()
+				return
+			}
 
-		// we send value into sim.simulatorStop channel in update() function only,
-		// update() is triggered when pod without nodename is handled.
-		if pod.Spec.NodeName == "" {
-			<-sim.simulatorStop
-		}
+			result := <-ch
+			if !result.scheduled {
+				if err := sim.fakeclient.CoreV1().Pods(pod.Namespace).Delete(podCtx, pod.Name, metav1.DeleteOptions{}); err != nil {
+					errMu.Lock()
+					if firstErr == nil {
+						firstErr = fmt.Errorf("%s %s/%s: %s", simontype.DeletePodError, pod.Namespace, pod.Name, err.Error())
+					}
+					errMu.Unlock()
+					return
+				}
+				podLogger.Info("pod could not be scheduled", "reason", result.reason)
+			}
+			results[n] = result
 
-		if strings.Contains(sim.status.stopReason, "failed") {
-			if err := sim.fakeclient.CoreV1().Pods(pod.Namespace).Delete(ctx, pod.Name, metav1.DeleteOptions{}); err != nil {
-				return nil, fmt.Errorf("%s %s/%s: %s", simontype.DeletePodError, pod.Namespace, pod.Name, err.Error())
+			if !sim.disablePTerm {
+				progressMu.Lock()
+				progressBar.Increment()
+				progressMu.Unlock()
 			}
+		}()
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+
+	var failedPods []UnscheduledPod
+	for n, result := range results {
+		if !result.scheduled {
 			failedPods = append(failedPods, UnscheduledPod{
-				Pod:    pod,
-				Reason: sim.status.stopReason,
+				Pod:    pods[n],
+				Reason: result.reason,
 			})
-			sim.status.stopReason = ""
-		}
-		if !sim.disablePTerm {
-			progressBar.Increment()
 		}
 	}
 	return failedPods, nil
@@ -337,16 +464,7 @@ func (sim *Simulator) schedulePods(ctx context.Context, pods []*corev1.Pod) ([]U
 
 func (sim *Simulator) Close() {
 	sim.scheduleOneCancelFunc()
-	testpod := test.MakeFakePod("test", "test", "", "")
-	_, err := sim.fakeclient.CoreV1().Pods("test").Create(context.TODO(), testpod, metav1.CreateOptions{})
-	if err != nil {
-		fmt.Printf("simon close with error: %s\n", err.Error())
-	}
-	if testpod.Spec.NodeName == "" {
-		<-sim.simulatorStop
-	}
 	sim.cancelFunc()
-	close(sim.simulatorStop)
 	sim.eventBroadcaster.Shutdown()
 }
 
@@ -433,25 +551,48 @@ func (sim *Simulator) syncClusterResourceList(resourceList ResourceTypes) (*Simu
 	}, nil
 }
 
+// update is the pod informer's update handler. It resolves a terminal
+// scheduling outcome for pod, if any, and delivers it to the channel that
+// schedulePods registered for that pod's namespace/name.
 func (sim *Simulator) update(pod *corev1.Pod) {
-	var stop bool = false
-	var stopReason string
-	var stopMessage string
-	for _, podCondition := range pod.Status.Conditions {
-		// log.Infof("podCondition %v", podCondition)
-		stop = podCondition.Type == corev1.PodScheduled && podCondition.Status == corev1.ConditionFalse && podCondition.Reason == corev1.PodReasonUnschedulable
-		if stop {
-			stopReason = podCondition.Reason
-			stopMessage = podCondition.Message
-			// fmt.Printf("stop is true: %s %s\n", stopReason, stopMessage)
-			break
-		}
+	result, terminal := schedResultForPod(pod)
+	if !terminal {
+		return
+	}
+
+	podKey := types.NamespacedName{Namespace: pod.Namespace, Name: pod.Name}
+	sim.pendingMu.Lock()
+	ch, ok := sim.pending[podKey]
+	if ok {
+		delete(sim.pending, podKey)
 	}
-	// Only for pending pods provisioned by simon
-	if stop {
-		sim.status.stopReason = fmt.Sprintf("failed to schedule pod (%s/%s): %s: %s", pod.Namespace, pod.Name, stopReason, stopMessage)
+	sim.pendingMu.Unlock()
+	if !ok {
+		return
 	}
-	sim.simulatorStop <- struct{}{}
+
+	if !result.scheduled {
+		klog.FromContext(sim.ctx).WithValues("pod", klog.KObj(pod), "namespace", pod.Namespace).
+			V(2).Info("pod scheduling stopped", "reason", result.reason)
+	}
+	ch <- result
+}
+
+// schedResultForPod reports the terminal scheduling outcome of pod, if it has
+// reached one: either bound to a node, or marked unschedulable. The second
+// return value is false while the pod is still being considered.
+func schedResultForPod(pod *corev1.Pod) (schedResult, bool) {
+	if pod.Spec.NodeName != "" {
+		return schedResult{scheduled: true}, true
+	}
+	for _, cond := range pod.Status.Conditions {
+		if cond.Type == corev1.PodScheduled && cond.Status == corev1.ConditionFalse && cond.Reason == corev1.PodReasonUnschedulable {
+			return schedResult{
+				reason: fmt.Sprintf("failed to schedule pod (%s/%s): %s: %s", pod.Namespace, pod.Name, cond.Reason, cond.Message),
+			}, true
+		}
+	}
+	return schedResult{}, false
 }
 
 // WithKubeConfig sets kubeconfig for Simulator, the default value is ""
@@ -480,112 +621,369 @@ func WithPatchPodsFuncMap(patchPodsFuncMap PatchPodsFuncMap) Option {
 	}
 }
 
+// WithAutoMigrationPlugins registers AutoMigrationPlugins by workload kind
+// for RunAutoMigration, merging into (and overriding, on a matching key) the
+// built-in Deployment/ReplicaSet/StatefulSet plugins. Use this to tune the
+// built-ins' threshold or replace one of them; only the "Deployment",
+// "ReplicaSet" and "StatefulSet" keys are ever evaluated by RunAutoMigration.
+func WithAutoMigrationPlugins(plugins AutoMigrationPluginMap) Option {
+	return func(o *simulatorOptions) {
+		o.autoMigrationPlugins = plugins
+	}
+}
+
 func WithScheduleConfigOpts(scheduleConfigOpts []ScheduleConfigOption) Option {
 	return func(o *simulatorOptions) {
 		o.scheduleConfigOpts = scheduleConfigOpts
 	}
 }
 
+// WithSharedInformerFactory makes the simulator reuse a pre-built informer
+// factory instead of creating its own, so a caller embedding the simulator
+// in a controller doesn't pay for a second full cache. The factory is
+// consumed by the first rebuild of the fake cluster; a later Restore falls
+// back to creating its own factory.
+func WithSharedInformerFactory(factory informers.SharedInformerFactory) Option {
+	return func(o *simulatorOptions) {
+		o.sharedInformerFactory = factory
+	}
+}
+
+// WithScoringPolicy sets the node-selection scoring policy (one of the
+// ScoringPolicy* constants), the default is ScoringPolicyLeastAllocated. An
+// empty policy is ignored so callers can pass through an unset Options field.
+func WithScoringPolicy(policy string) Option {
+	return func(o *simulatorOptions) {
+		if policy != "" {
+			o.scoringPolicy = policy
+		}
+	}
+}
+
+// WithGPUScoringPolicy sets the device-selection policy among the GPUs on a
+// chosen node, independently of WithScoringPolicy's node-selection policy.
+//
+// This is currently a no-op past simulatorOptions: per-device GPU scoring is
+// owned by the Simon GPU-share scheduler plugin (pkg/simulator/plugin), not
+// by this package, and that plugin does not yet read gpuScoringPolicy from
+// its PluginConfig args. Wiring it through requires extending that plugin,
+// not this one.
+func WithGPUScoringPolicy(policy string) Option {
+	return func(o *simulatorOptions) {
+		if policy != "" {
+			o.gpuScoringPolicy = policy
+		}
+	}
+}
+
+// nodeResourcesFitScoringStrategy maps a ScoringPolicy* constant to the
+// built-in NodeResourcesFit plugin's ScoringStrategyType. Binpack is
+// expressed as MostAllocated (pack nodes tightly) and spread as
+// LeastAllocated (favor nodes with the most headroom), since NodeResourcesFit
+// has no separate "binpack"/"spread" strategy of its own.
+func nodeResourcesFitScoringStrategy(policy string) kubeschedulerconfig.ScoringStrategyType {
+	switch policy {
+	case ScoringPolicyBinpack, ScoringPolicyMostAllocated:
+		return kubeschedulerconfig.MostAllocated
+	default:
+		return kubeschedulerconfig.LeastAllocated
+	}
+}
+
+// applyScoringPolicy points every profile's NodeResourcesFit plugin at the
+// ScoringStrategy matching scoringPolicy, so WithScoringPolicy actually
+// changes which node a pod is scheduled onto instead of being recorded and
+// ignored.
+func applyScoringPolicy(profiles []kubeschedulerconfig.KubeSchedulerProfile, scoringPolicy string) {
+	strategyType := nodeResourcesFitScoringStrategy(scoringPolicy)
+	for i := range profiles {
+		profile := &profiles[i]
+		var args *kubeschedulerconfig.NodeResourcesFitArgs
+		for j := range profile.PluginConfig {
+			if profile.PluginConfig[j].Name == nodeResourcesFitPluginName {
+				if existing, ok := profile.PluginConfig[j].Args.(*kubeschedulerconfig.NodeResourcesFitArgs); ok {
+					args = existing
+				}
+				break
+			}
+		}
+		if args == nil {
+			args = &kubeschedulerconfig.NodeResourcesFitArgs{}
+			profile.PluginConfig = append(profile.PluginConfig, kubeschedulerconfig.PluginConfig{
+				Name: nodeResourcesFitPluginName,
+				Args: args,
+			})
+		}
+		if args.ScoringStrategy == nil {
+			args.ScoringStrategy = &kubeschedulerconfig.ScoringStrategy{}
+		}
+		args.ScoringStrategy.Type = strategyType
+	}
+}
+
+// nodeResourcesFitPluginName is the name the scheduler framework registers
+// the built-in NodeResourcesFit plugin under.
+const nodeResourcesFitPluginName = "NodeResourcesFit"
+
+// enableNumaZoneFitPlugin enables NumaZoneFitPlugin on every profile's
+// Filter and Score extension points. GetSchedulerConfig builds profiles with
+// no knowledge of this package-local plugin, so without this it would sit
+// registered in bindRegistry but never actually run.
+func enableNumaZoneFitPlugin(profiles []kubeschedulerconfig.KubeSchedulerProfile) {
+	for i := range profiles {
+		profile := &profiles[i]
+		if profile.Plugins == nil {
+			profile.Plugins = &kubeschedulerconfig.Plugins{}
+		}
+		profile.Plugins.Filter.Enabled = append(profile.Plugins.Filter.Enabled,
+			kubeschedulerconfig.Plugin{Name: NumaZoneFitPluginName})
+		profile.Plugins.Score.Enabled = append(profile.Plugins.Score.Enabled,
+			kubeschedulerconfig.Plugin{Name: NumaZoneFitPluginName, Weight: 1})
+	}
+}
+
+// WithLogger sets the logr.Logger that Simulator threads through its context
+// via klog.NewContext, replacing the klog.Background() default. Passing a
+// zero-value logr.Logger is ignored.
+func WithLogger(logger logr.Logger) Option {
+	return func(o *simulatorOptions) {
+		if logger.GetSink() != nil {
+			o.logger = logger
+		}
+	}
+}
+
+// WithParallelism sets how many pods schedulePods submits and tracks
+// concurrently, instead of waiting for each pod to reach a terminal outcome
+// before creating the next one. The default, 1, matches the previous
+// strictly serial behavior. n <= 0 is ignored.
+func WithParallelism(n int) Option {
+	return func(o *simulatorOptions) {
+		if n > 0 {
+			o.parallelism = n
+		}
+	}
+}
+
 func DisablePTerm(disablePTerm bool) Option {
 	return func(o *simulatorOptions) {
 		o.disablePTerm = disablePTerm
 	}
 }
 
+// collectListLimit bounds how many objects CreateClusterResourceFromClient
+// asks the apiserver for per page, so a single List response never has to
+// hold the whole corpus of a resource kind in memory.
+const collectListLimit = 500
+
+type collectOptions struct {
+	namespaces    []string
+	labelSelector string
+	fieldSelector string
+}
+
+// CollectOption configures CreateClusterResourceFromClient.
+type CollectOption func(*collectOptions)
+
+// WithNamespaces restricts collection to the given namespaces instead of the
+// whole cluster. An empty/unset list means all namespaces.
+func WithNamespaces(namespaces []string) CollectOption {
+	return func(o *collectOptions) {
+		o.namespaces = namespaces
+	}
+}
+
+// WithLabelSelector restricts collection to objects matching selector.
+func WithLabelSelector(selector string) CollectOption {
+	return func(o *collectOptions) {
+		o.labelSelector = selector
+	}
+}
+
+// WithFieldSelector restricts collection to objects matching selector.
+func WithFieldSelector(selector string) CollectOption {
+	return func(o *collectOptions) {
+		o.fieldSelector = selector
+	}
+}
+
+// namespacesOrAll returns o.namespaces, or a single metav1.NamespaceAll entry
+// when none were configured, so callers can always range over the result.
+func (o collectOptions) namespacesOrAll() []string {
+	if len(o.namespaces) == 0 {
+		return []string{metav1.NamespaceAll}
+	}
+	return o.namespaces
+}
+
+// listPaginated drives list across as many pages as the apiserver returns,
+// following ListOptions.Continue until it is exhausted, so a single response
+// never has to hold a resource kind's full corpus in memory.
+func listPaginated(opts metav1.ListOptions, list func(metav1.ListOptions) (string, error)) error {
+	opts.Limit = collectListLimit
+	for {
+		cont, err := list(opts)
+		if err != nil {
+			return err
+		}
+		if cont == "" {
+			return nil
+		}
+		opts.Continue = cont
+	}
+}
+
 // CreateClusterResourceFromClient returns a ResourceTypes struct by kube-client that connects a real cluster
-func CreateClusterResourceFromClient(client externalclientset.Interface, disablePTerm bool) (ResourceTypes, error) {
+func CreateClusterResourceFromClient(client externalclientset.Interface, disablePTerm bool, opts ...CollectOption) (ResourceTypes, error) {
 	var resource ResourceTypes
-	var err error
 	var spinner *pterm.SpinnerPrinter
 	if !disablePTerm {
 		spinner, _ = pterm.DefaultSpinner.WithShowTimer().Start("get resource info from kube client")
 	}
 
+	var options collectOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+	baseListOpts := metav1.ListOptions{
+		LabelSelector: options.labelSelector,
+		FieldSelector: options.fieldSelector,
+	}
+
 	trace := utiltrace.New("Trace CreateClusterResourceFromClient")
 	defer trace.LogIfLong(100 * time.Millisecond)
-	nodeItems, err := client.CoreV1().Nodes().List(context.TODO(), metav1.ListOptions{})
-	if err != nil {
-		return resource, fmt.Errorf("unable to list nodes: %v", err)
-	}
-	for _, item := range nodeItems.Items {
-		newItem := item
-		resource.Nodes = append(resource.Nodes, &newItem)
+
+	if err := listPaginated(baseListOpts, func(listOpts metav1.ListOptions) (string, error) {
+		page, err := client.CoreV1().Nodes().List(context.TODO(), listOpts)
+		if err != nil {
+			return "", fmt.Errorf("unable to list nodes: %v", err)
+		}
+		for _, item := range page.Items {
+			newItem := item
+			resource.Nodes = append(resource.Nodes, &newItem)
+		}
+		return page.Continue, nil
+	}); err != nil {
+		return resource, err
 	}
 	trace.Step("CreateClusterResourceFromClient: List Node done")
 
-	// We will regenerate pods of all workloads in the follow-up stage.
-	podItems, err := client.CoreV1().Pods(metav1.NamespaceAll).List(context.TODO(), metav1.ListOptions{ResourceVersion: "0"})
-	if err != nil {
-		return resource, fmt.Errorf("unable to list pods: %v", err)
+	storageClassListOpts := baseListOpts
+	if err := listPaginated(storageClassListOpts, func(listOpts metav1.ListOptions) (string, error) {
+		page, err := client.StorageV1().StorageClasses().List(context.TODO(), listOpts)
+		if err != nil {
+			return "", fmt.Errorf("unable to list storage classes: %v", err)
+		}
+		for _, item := range page.Items {
+			newItem := item
+			resource.StorageClasss = append(resource.StorageClasss, &newItem)
+		}
+		return page.Continue, nil
+	}); err != nil {
+		return resource, err
 	}
-	pendingPods := []*corev1.Pod{}
-	for _, item := range podItems.Items {
-		if !utils.OwnedByDaemonset(item.OwnerReferences) && item.DeletionTimestamp == nil {
-			if item.Status.Phase == corev1.PodRunning {
-				newItem := item
-				resource.Pods = append(resource.Pods, &newItem)
-			} else if item.Status.Phase == corev1.PodPending {
-				newItem := item
-				pendingPods = append(pendingPods, &newItem)
+
+	var pendingPods []*corev1.Pod
+	for _, namespace := range options.namespacesOrAll() {
+		podListOpts := baseListOpts
+		podListOpts.ResourceVersion = "0"
+		// We will regenerate pods of all workloads in the follow-up stage.
+		if err := listPaginated(podListOpts, func(listOpts metav1.ListOptions) (string, error) {
+			page, err := client.CoreV1().Pods(namespace).List(context.TODO(), listOpts)
+			if err != nil {
+				return "", fmt.Errorf("unable to list pods: %v", err)
+			}
+			for _, item := range page.Items {
+				if !utils.OwnedByDaemonset(item.OwnerReferences) && item.DeletionTimestamp == nil {
+					if item.Status.Phase == corev1.PodRunning {
+						newItem := item
+						resource.Pods = append(resource.Pods, &newItem)
+					} else if item.Status.Phase == corev1.PodPending {
+						newItem := item
+						pendingPods = append(pendingPods, &newItem)
+					}
+				}
 			}
+			return page.Continue, nil
+		}); err != nil {
+			return resource, err
 		}
 	}
 	resource.Pods = append(resource.Pods, pendingPods...)
 	trace.Step("CreateClusterResourceFromClient: List Pod done")
 
-	pdbItems, err := client.PolicyV1beta1().PodDisruptionBudgets(metav1.NamespaceAll).List(context.TODO(), metav1.ListOptions{})
-	if err != nil {
-		return resource, fmt.Errorf("unable to list PDBs: %v", err)
-	}
-	for _, item := range pdbItems.Items {
-		newItem := item
-		resource.PodDisruptionBudgets = append(resource.PodDisruptionBudgets, &newItem)
-	}
+	for _, namespace := range options.namespacesOrAll() {
+		if err := listPaginated(baseListOpts, func(listOpts metav1.ListOptions) (string, error) {
+			page, err := client.PolicyV1beta1().PodDisruptionBudgets(namespace).List(context.TODO(), listOpts)
+			if err != nil {
+				return "", fmt.Errorf("unable to list PDBs: %v", err)
+			}
+			for _, item := range page.Items {
+				newItem := item
+				resource.PodDisruptionBudgets = append(resource.PodDisruptionBudgets, &newItem)
+			}
+			return page.Continue, nil
+		}); err != nil {
+			return resource, err
+		}
 
-	serviceItems, err := client.CoreV1().Services(metav1.NamespaceAll).List(context.TODO(), metav1.ListOptions{})
-	if err != nil {
-		return resource, fmt.Errorf("unable to list services: %v", err)
-	}
-	for _, item := range serviceItems.Items {
-		newItem := item
-		resource.Services = append(resource.Services, &newItem)
-	}
+		if err := listPaginated(baseListOpts, func(listOpts metav1.ListOptions) (string, error) {
+			page, err := client.CoreV1().Services(namespace).List(context.TODO(), listOpts)
+			if err != nil {
+				return "", fmt.Errorf("unable to list services: %v", err)
+			}
+			for _, item := range page.Items {
+				newItem := item
+				resource.Services = append(resource.Services, &newItem)
+			}
+			return page.Continue, nil
+		}); err != nil {
+			return resource, err
+		}
 
-	storageClassesItems, err := client.StorageV1().StorageClasses().List(context.TODO(), metav1.ListOptions{})
-	if err != nil {
-		return resource, fmt.Errorf("unable to list storage classes: %v", err)
-	}
-	for _, item := range storageClassesItems.Items {
-		newItem := item
-		resource.StorageClasss = append(resource.StorageClasss, &newItem)
-	}
+		if err := listPaginated(baseListOpts, func(listOpts metav1.ListOptions) (string, error) {
+			page, err := client.CoreV1().PersistentVolumeClaims(namespace).List(context.TODO(), listOpts)
+			if err != nil {
+				return "", fmt.Errorf("unable to list pvcs: %v", err)
+			}
+			for _, item := range page.Items {
+				newItem := item
+				resource.PersistentVolumeClaims = append(resource.PersistentVolumeClaims, &newItem)
+			}
+			return page.Continue, nil
+		}); err != nil {
+			return resource, err
+		}
 
-	pvcItems, err := client.CoreV1().PersistentVolumeClaims(metav1.NamespaceAll).List(context.TODO(), metav1.ListOptions{})
-	if err != nil {
-		return resource, fmt.Errorf("unable to list pvcs: %v", err)
-	}
-	for _, item := range pvcItems.Items {
-		newItem := item
-		resource.PersistentVolumeClaims = append(resource.PersistentVolumeClaims, &newItem)
-	}
+		if err := listPaginated(baseListOpts, func(listOpts metav1.ListOptions) (string, error) {
+			page, err := client.CoreV1().ConfigMaps(namespace).List(context.TODO(), listOpts)
+			if err != nil {
+				return "", fmt.Errorf("unable to list configmaps: %v", err)
+			}
+			for _, item := range page.Items {
+				newItem := item
+				resource.ConfigMaps = append(resource.ConfigMaps, &newItem)
+			}
+			return page.Continue, nil
+		}); err != nil {
+			return resource, err
+		}
 
-	cmItems, err := client.CoreV1().ConfigMaps(metav1.NamespaceAll).List(context.TODO(), metav1.ListOptions{})
-	if err != nil {
-		return resource, fmt.Errorf("unable to list configmaps: %v", err)
-	}
-	for _, item := range cmItems.Items {
-		newItem := item
-		resource.ConfigMaps = append(resource.ConfigMaps, &newItem)
+		if err := listPaginated(baseListOpts, func(listOpts metav1.ListOptions) (string, error) {
+			page, err := client.AppsV1().DaemonSets(namespace).List(context.TODO(), listOpts)
+			if err != nil {
+				return "", fmt.Errorf("unable to list daemon sets: %v", err)
+			}
+			for _, item := range page.Items {
+				newItem := item
+				resource.DaemonSets = append(resource.DaemonSets, &newItem)
+			}
+			return page.Continue, nil
+		}); err != nil {
+			return resource, err
+		}
 	}
 
-	daemonSetItems, err := client.AppsV1().DaemonSets(metav1.NamespaceAll).List(context.TODO(), metav1.ListOptions{})
-	if err != nil {
-		return resource, fmt.Errorf("unable to list daemon sets: %v", err)
-	}
-	for _, item := range daemonSetItems.Items {
-		newItem := item
-		resource.DaemonSets = append(resource.DaemonSets, &newItem)
-	}
 	if !disablePTerm {
 		spinner.Success("get resource info from kube client done!")
 	}
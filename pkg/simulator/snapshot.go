@@ -0,0 +1,292 @@
+package simulator
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	externalclientset "k8s.io/client-go/kubernetes"
+	fakeclientset "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/yaml"
+)
+
+// ClusterSnapshotVersion is bumped whenever ClusterSnapshot's shape changes
+// in a way that older readers can't handle.
+const ClusterSnapshotVersion = "v1"
+
+// ClusterSnapshot is a point-in-time, serializable copy of everything a
+// Simulator's fakeclient holds. It lets callers run many "what-if"
+// ScheduleApp calls from the same baseline without re-parsing YAML
+// directories via CreateClusterResourceFromClusterConfig each time, and lets
+// a snapshot captured from a real cluster via CreateClusterResourceFromClient
+// be persisted to disk and shared.
+type ClusterSnapshot struct {
+	APIVersion string `json:"apiVersion"`
+
+	Nodes                  []*corev1.Node                       `json:"nodes,omitempty"`
+	Pods                   []*corev1.Pod                        `json:"pods,omitempty"`
+	PersistentVolumeClaims []*corev1.PersistentVolumeClaim      `json:"persistentVolumeClaims,omitempty"`
+	PersistentVolumes      []*corev1.PersistentVolume           `json:"persistentVolumes,omitempty"`
+	PodDisruptionBudgets   []*policyv1beta1.PodDisruptionBudget `json:"podDisruptionBudgets,omitempty"`
+	Services               []*corev1.Service                    `json:"services,omitempty"`
+	ConfigMaps             []*corev1.ConfigMap                  `json:"configMaps,omitempty"`
+	StorageClasses         []*storagev1.StorageClass            `json:"storageClasses,omitempty"`
+	Deployments            []*appsv1.Deployment                 `json:"deployments,omitempty"`
+	ReplicaSets            []*appsv1.ReplicaSet                 `json:"replicaSets,omitempty"`
+	StatefulSets           []*appsv1.StatefulSet                `json:"statefulSets,omitempty"`
+	DaemonSets             []*appsv1.DaemonSet                  `json:"daemonSets,omitempty"`
+}
+
+// Snapshot captures the current state of every resource kind the Simulator's
+// fakeclient tracks.
+func (sim *Simulator) Snapshot() (*ClusterSnapshot, error) {
+	ctx := sim.ctx
+	snapshot := &ClusterSnapshot{APIVersion: ClusterSnapshotVersion}
+
+	nodes, err := sim.fakeclient.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to snapshot nodes: %v", err)
+	}
+	for i := range nodes.Items {
+		snapshot.Nodes = append(snapshot.Nodes, &nodes.Items[i])
+	}
+
+	pods, err := sim.fakeclient.CoreV1().Pods(corev1.NamespaceAll).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to snapshot pods: %v", err)
+	}
+	for i := range pods.Items {
+		snapshot.Pods = append(snapshot.Pods, &pods.Items[i])
+	}
+
+	pvcs, err := sim.fakeclient.CoreV1().PersistentVolumeClaims(corev1.NamespaceAll).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to snapshot persistent volume claims: %v", err)
+	}
+	for i := range pvcs.Items {
+		snapshot.PersistentVolumeClaims = append(snapshot.PersistentVolumeClaims, &pvcs.Items[i])
+	}
+
+	pvs, err := sim.fakeclient.CoreV1().PersistentVolumes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to snapshot persistent volumes: %v", err)
+	}
+	for i := range pvs.Items {
+		snapshot.PersistentVolumes = append(snapshot.PersistentVolumes, &pvs.Items[i])
+	}
+
+	pdbs, err := sim.fakeclient.PolicyV1beta1().PodDisruptionBudgets(corev1.NamespaceAll).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to snapshot pod disruption budgets: %v", err)
+	}
+	for i := range pdbs.Items {
+		snapshot.PodDisruptionBudgets = append(snapshot.PodDisruptionBudgets, &pdbs.Items[i])
+	}
+
+	services, err := sim.fakeclient.CoreV1().Services(corev1.NamespaceAll).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to snapshot services: %v", err)
+	}
+	for i := range services.Items {
+		snapshot.Services = append(snapshot.Services, &services.Items[i])
+	}
+
+	cms, err := sim.fakeclient.CoreV1().ConfigMaps(corev1.NamespaceAll).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to snapshot config maps: %v", err)
+	}
+	for i := range cms.Items {
+		snapshot.ConfigMaps = append(snapshot.ConfigMaps, &cms.Items[i])
+	}
+
+	scs, err := sim.fakeclient.StorageV1().StorageClasses().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to snapshot storage classes: %v", err)
+	}
+	for i := range scs.Items {
+		snapshot.StorageClasses = append(snapshot.StorageClasses, &scs.Items[i])
+	}
+
+	deploys, err := sim.fakeclient.AppsV1().Deployments(corev1.NamespaceAll).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to snapshot deployments: %v", err)
+	}
+	for i := range deploys.Items {
+		snapshot.Deployments = append(snapshot.Deployments, &deploys.Items[i])
+	}
+
+	rss, err := sim.fakeclient.AppsV1().ReplicaSets(corev1.NamespaceAll).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to snapshot replica sets: %v", err)
+	}
+	for i := range rss.Items {
+		snapshot.ReplicaSets = append(snapshot.ReplicaSets, &rss.Items[i])
+	}
+
+	stss, err := sim.fakeclient.AppsV1().StatefulSets(corev1.NamespaceAll).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to snapshot stateful sets: %v", err)
+	}
+	for i := range stss.Items {
+		snapshot.StatefulSets = append(snapshot.StatefulSets, &stss.Items[i])
+	}
+
+	dss, err := sim.fakeclient.AppsV1().DaemonSets(corev1.NamespaceAll).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to snapshot daemon sets: %v", err)
+	}
+	for i := range dss.Items {
+		snapshot.DaemonSets = append(snapshot.DaemonSets, &dss.Items[i])
+	}
+
+	return snapshot, nil
+}
+
+// Restore replaces the Simulator's entire fakeclient state with snapshot,
+// re-syncs the pod/node/etc. informers against the new client, and rebuilds
+// the scheduler so its caches can't see stale objects from before the
+// restore.
+func (sim *Simulator) Restore(snapshot *ClusterSnapshot) error {
+	if snapshot == nil {
+		return fmt.Errorf("cannot restore from a nil snapshot")
+	}
+	if snapshot.APIVersion != ClusterSnapshotVersion {
+		return fmt.Errorf("unsupported cluster snapshot version %q, expected %q", snapshot.APIVersion, ClusterSnapshotVersion)
+	}
+	logger := klog.FromContext(sim.ctx)
+
+	// Stop the scheduler loop bound to the cluster state we're about to
+	// replace, so it can't act on stale informer caches mid-restore.
+	sim.scheduleOneCancelFunc()
+
+	newFakeClient := fakeclientset.NewSimpleClientset()
+	if err := populateFakeClient(sim.ctx, newFakeClient, snapshot); err != nil {
+		return fmt.Errorf("failed to restore cluster snapshot: %v", err)
+	}
+
+	sim.pendingMu.Lock()
+	sim.pending = make(map[types.NamespacedName]chan schedResult)
+	sim.pendingMu.Unlock()
+
+	if err := sim.rebuildCluster(newFakeClient); err != nil {
+		return fmt.Errorf("failed to rebuild simulator around restored snapshot: %v", err)
+	}
+
+	sim.scheduleOneCtx, sim.scheduleOneCancelFunc = context.WithCancel(sim.ctx)
+	sim.runScheduler()
+
+	logger.Info("restored cluster from snapshot",
+		"nodes", len(snapshot.Nodes), "pods", len(snapshot.Pods))
+	return nil
+}
+
+// populateFakeClient recreates every object recorded in snapshot inside
+// client, in dependency order (namespaced-scoped objects after the
+// cluster-scoped ones they may reference).
+func populateFakeClient(ctx context.Context, client externalclientset.Interface, snapshot *ClusterSnapshot) error {
+	for _, item := range snapshot.Nodes {
+		if _, err := client.CoreV1().Nodes().Create(ctx, item, metav1.CreateOptions{}); err != nil {
+			return fmt.Errorf("unable to restore node: %v", err)
+		}
+	}
+	for _, item := range snapshot.StorageClasses {
+		if _, err := client.StorageV1().StorageClasses().Create(ctx, item, metav1.CreateOptions{}); err != nil {
+			return fmt.Errorf("unable to restore storage class: %v", err)
+		}
+	}
+	for _, item := range snapshot.PersistentVolumes {
+		if _, err := client.CoreV1().PersistentVolumes().Create(ctx, item, metav1.CreateOptions{}); err != nil {
+			return fmt.Errorf("unable to restore persistent volume: %v", err)
+		}
+	}
+	for _, item := range snapshot.PersistentVolumeClaims {
+		if _, err := client.CoreV1().PersistentVolumeClaims(item.Namespace).Create(ctx, item, metav1.CreateOptions{}); err != nil {
+			return fmt.Errorf("unable to restore persistent volume claim: %v", err)
+		}
+	}
+	for _, item := range snapshot.PodDisruptionBudgets {
+		if _, err := client.PolicyV1beta1().PodDisruptionBudgets(item.Namespace).Create(ctx, item, metav1.CreateOptions{}); err != nil {
+			return fmt.Errorf("unable to restore PDB: %v", err)
+		}
+	}
+	for _, item := range snapshot.Services {
+		if _, err := client.CoreV1().Services(item.Namespace).Create(ctx, item, metav1.CreateOptions{}); err != nil {
+			return fmt.Errorf("unable to restore service: %v", err)
+		}
+	}
+	for _, item := range snapshot.ConfigMaps {
+		if _, err := client.CoreV1().ConfigMaps(item.Namespace).Create(ctx, item, metav1.CreateOptions{}); err != nil {
+			return fmt.Errorf("unable to restore configmap: %v", err)
+		}
+	}
+	for _, item := range snapshot.Deployments {
+		if _, err := client.AppsV1().Deployments(item.Namespace).Create(ctx, item, metav1.CreateOptions{}); err != nil {
+			return fmt.Errorf("unable to restore deployment: %v", err)
+		}
+	}
+	for _, item := range snapshot.ReplicaSets {
+		if _, err := client.AppsV1().ReplicaSets(item.Namespace).Create(ctx, item, metav1.CreateOptions{}); err != nil {
+			return fmt.Errorf("unable to restore replica set: %v", err)
+		}
+	}
+	for _, item := range snapshot.StatefulSets {
+		if _, err := client.AppsV1().StatefulSets(item.Namespace).Create(ctx, item, metav1.CreateOptions{}); err != nil {
+			return fmt.Errorf("unable to restore stateful set: %v", err)
+		}
+	}
+	for _, item := range snapshot.DaemonSets {
+		if _, err := client.AppsV1().DaemonSets(item.Namespace).Create(ctx, item, metav1.CreateOptions{}); err != nil {
+			return fmt.Errorf("unable to restore daemon set: %v", err)
+		}
+	}
+	for _, item := range snapshot.Pods {
+		if _, err := client.CoreV1().Pods(item.Namespace).Create(ctx, item, metav1.CreateOptions{}); err != nil {
+			return fmt.Errorf("unable to restore pod: %v", err)
+		}
+	}
+	return nil
+}
+
+// WriteClusterSnapshotFile serializes snapshot to path as JSON or YAML,
+// chosen by its extension (".json" for JSON, anything else for YAML).
+func WriteClusterSnapshotFile(path string, snapshot *ClusterSnapshot) error {
+	var data []byte
+	var err error
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		data, err = json.MarshalIndent(snapshot, "", "  ")
+	} else {
+		data, err = yaml.Marshal(snapshot)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to marshal cluster snapshot: %v", err)
+	}
+	if err := ioutil.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write cluster snapshot to %s: %v", path, err)
+	}
+	return nil
+}
+
+// ReadClusterSnapshotFile reads back a ClusterSnapshot written by
+// WriteClusterSnapshotFile. YAML is a superset of JSON, so the same
+// unmarshaler handles both extensions.
+func ReadClusterSnapshotFile(path string) (*ClusterSnapshot, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cluster snapshot from %s: %v", path, err)
+	}
+	var snapshot ClusterSnapshot
+	if err := yaml.Unmarshal(data, &snapshot); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal cluster snapshot from %s: %v", path, err)
+	}
+	return &snapshot, nil
+}
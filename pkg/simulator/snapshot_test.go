@@ -0,0 +1,80 @@
+package simulator
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	fakeclientset "k8s.io/client-go/kubernetes/fake"
+)
+
+func TestSnapshotRoundTripsFakeClientState(t *testing.T) {
+	fakeclient := fakeclientset.NewSimpleClientset()
+	ctx := context.Background()
+
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+		Status: corev1.NodeStatus{
+			Allocatable: corev1.ResourceList{
+				corev1.ResourceCPU: resource.MustParse("4"),
+			},
+		},
+	}
+	if _, err := fakeclient.CoreV1().Nodes().Create(ctx, node, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("failed to seed node: %v", err)
+	}
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "pod-1"}}
+	if _, err := fakeclient.CoreV1().Pods("default").Create(ctx, pod, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("failed to seed pod: %v", err)
+	}
+
+	sim := &Simulator{fakeclient: fakeclient, ctx: ctx}
+
+	snapshot, err := sim.Snapshot()
+	if err != nil {
+		t.Fatalf("Snapshot() returned error: %v", err)
+	}
+	if snapshot.APIVersion != ClusterSnapshotVersion {
+		t.Fatalf("APIVersion = %q, want %q", snapshot.APIVersion, ClusterSnapshotVersion)
+	}
+	if len(snapshot.Nodes) != 1 || snapshot.Nodes[0].Name != "node-1" {
+		t.Fatalf("expected 1 snapshotted node named node-1, got %+v", snapshot.Nodes)
+	}
+	if len(snapshot.Pods) != 1 || snapshot.Pods[0].Name != "pod-1" {
+		t.Fatalf("expected 1 snapshotted pod named pod-1, got %+v", snapshot.Pods)
+	}
+
+	restoredClient := fakeclientset.NewSimpleClientset()
+	if err := populateFakeClient(ctx, restoredClient, snapshot); err != nil {
+		t.Fatalf("populateFakeClient() returned error: %v", err)
+	}
+
+	restoredNodes, err := restoredClient.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		t.Fatalf("failed to list restored nodes: %v", err)
+	}
+	if len(restoredNodes.Items) != 1 || restoredNodes.Items[0].Name != "node-1" {
+		t.Fatalf("expected restored client to have 1 node named node-1, got %+v", restoredNodes.Items)
+	}
+
+	restoredPods, err := restoredClient.CoreV1().Pods("default").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		t.Fatalf("failed to list restored pods: %v", err)
+	}
+	if len(restoredPods.Items) != 1 || restoredPods.Items[0].Name != "pod-1" {
+		t.Fatalf("expected restored client to have 1 pod named pod-1, got %+v", restoredPods.Items)
+	}
+}
+
+func TestRestoreRejectsNilOrUnsupportedVersion(t *testing.T) {
+	sim := &Simulator{ctx: context.Background()}
+
+	if err := sim.Restore(nil); err == nil {
+		t.Fatal("expected Restore(nil) to return an error")
+	}
+	if err := sim.Restore(&ClusterSnapshot{APIVersion: "v999"}); err == nil {
+		t.Fatal("expected Restore() to reject an unsupported snapshot version")
+	}
+}
@@ -0,0 +1,141 @@
+package simulator
+
+import (
+	"encoding/json"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Node/pod annotations describing NUMA topology, modeled after the kubelet
+// Topology Manager / PodResources allocatable API. These are best-effort
+// hints: NumaZoneFitPlugin (numa_plugin.go) is the FilterPlugin/ScorePlugin
+// that actually enforces them during scheduling, against each zone's
+// declared capacity rather than live per-zone usage, since tracking
+// per-zone allocation across pods already placed on a node would require
+// the same device-accounting state the out-of-tree open-gpu-share plugin
+// owns for GPUs.
+const (
+	AnnoNodeTopologyHint         = "simon/topology-hint"
+	AnnoPodTopologyPolicy        = "simon/topology-policy"
+	TopologyPolicySingleNumaNode = "single-numa-node"
+	TopologyPolicyRestricted     = "restricted"
+	TopologyPolicyBestEffort     = "best-effort"
+)
+
+// NumaZone describes one NUMA node's share of a machine's CPUs, memory and
+// GPUs.
+type NumaZone struct {
+	ID          int   `json:"id"`
+	CPUIDs      []int `json:"cpuIds"`
+	MemoryBytes int64 `json:"memoryBytes"`
+	GPUIndices  []int `json:"gpuIndices"`
+}
+
+// TopologyHint is the per-node NUMA layout, stored as JSON in
+// AnnoNodeTopologyHint.
+type TopologyHint struct {
+	Zones []NumaZone `json:"zones"`
+}
+
+// ParseTopologyHint reads and unmarshals the NUMA layout annotation of node.
+// It returns (nil, nil) when the node carries no topology hint.
+func ParseTopologyHint(node *corev1.Node) (*TopologyHint, error) {
+	raw, exist := node.Annotations[AnnoNodeTopologyHint]
+	if !exist {
+		return nil, nil
+	}
+	var hint TopologyHint
+	if err := json.Unmarshal([]byte(raw), &hint); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal topology hint of node(%s): %v", node.Name, err)
+	}
+	return &hint, nil
+}
+
+// PodTopologyPolicy returns the topology policy requested by pod, or "" when
+// the pod does not opt in to topology-aware placement.
+func PodTopologyPolicy(pod *corev1.Pod) string {
+	if pod.Annotations == nil {
+		return ""
+	}
+	return pod.Annotations[AnnoPodTopologyPolicy]
+}
+
+// zoneForGpuIndex returns the NumaZone that owns gpuIndex, if any.
+func zoneForGpuIndex(hint *TopologyHint, gpuIndex int) *NumaZone {
+	for i := range hint.Zones {
+		for _, idx := range hint.Zones[i].GPUIndices {
+			if idx == gpuIndex {
+				return &hint.Zones[i]
+			}
+		}
+	}
+	return nil
+}
+
+// IsNumaAligned reports whether the CPU/memory requests of pod, plus the GPU
+// it was bound to (gpuIndex, or -1 when the pod requests no GPU), all fall
+// within a single NUMA zone of hint. cpuMilli and memBytes are the pod's
+// total requested CPU (in milli-cores) and memory (in bytes).
+func IsNumaAligned(hint *TopologyHint, cpuMilli, memBytes int64, gpuIndex int) (aligned bool, reason string) {
+	if hint == nil || len(hint.Zones) == 0 {
+		return false, "node has no topology hint"
+	}
+
+	var candidateZones []*NumaZone
+	if gpuIndex >= 0 {
+		zone := zoneForGpuIndex(hint, gpuIndex)
+		if zone == nil {
+			return false, fmt.Sprintf("gpu index %d is not described by any NUMA zone", gpuIndex)
+		}
+		candidateZones = []*NumaZone{zone}
+	} else {
+		for i := range hint.Zones {
+			candidateZones = append(candidateZones, &hint.Zones[i])
+		}
+	}
+
+	for _, zone := range candidateZones {
+		if int64(len(zone.CPUIDs))*1000 >= cpuMilli && zone.MemoryBytes >= memBytes {
+			return true, ""
+		}
+	}
+	return false, "no single NUMA zone can satisfy the pod's cpu, memory and gpu requests together"
+}
+
+// numaZoneFitScore scores a node's NUMA zones for a pod's cpuMilli/memBytes/
+// gpuCount request against each zone's declared capacity: 0 when no zone can
+// fit the request, otherwise a value in [1, maxNumaZoneFitScore] that grows
+// the tighter the best-fitting zone's leftover CPU capacity is, so placements
+// that use a NUMA zone more fully are preferred over ones that leave it
+// mostly idle.
+func numaZoneFitScore(hint *TopologyHint, cpuMilli, memBytes, gpuCount int64) int64 {
+	if hint == nil {
+		return 0
+	}
+
+	best := int64(-1)
+	for i := range hint.Zones {
+		zone := &hint.Zones[i]
+		zoneCpuMilli := int64(len(zone.CPUIDs)) * 1000
+		if zoneCpuMilli == 0 || zoneCpuMilli < cpuMilli || zone.MemoryBytes < memBytes || int64(len(zone.GPUIndices)) < gpuCount {
+			continue
+		}
+		leftoverFrac := float64(zoneCpuMilli-cpuMilli) / float64(zoneCpuMilli)
+		score := int64(maxNumaZoneFitScore * (1 - leftoverFrac))
+		if score < 1 {
+			score = 1
+		}
+		if score > best {
+			best = score
+		}
+	}
+	if best < 0 {
+		return 0
+	}
+	return best
+}
+
+// maxNumaZoneFitScore is the ceiling numaZoneFitScore returns for a
+// perfectly-tight fit, matching framework.MaxNodeScore.
+const maxNumaZoneFitScore = 100
@@ -0,0 +1,51 @@
+package simulator
+
+import "testing"
+
+func twoZoneHint() *TopologyHint {
+	return &TopologyHint{
+		Zones: []NumaZone{
+			{ID: 0, CPUIDs: []int{0, 1, 2, 3}, MemoryBytes: 8 << 30, GPUIndices: []int{0}},
+			{ID: 1, CPUIDs: []int{4, 5, 6, 7}, MemoryBytes: 16 << 30, GPUIndices: []int{1}},
+		},
+	}
+}
+
+func TestNumaZoneFitScoreNoFittingZone(t *testing.T) {
+	hint := twoZoneHint()
+	if got := numaZoneFitScore(hint, 5000, 1<<30, 0); got != 0 {
+		t.Fatalf("expected 0 when no zone has enough CPU, got %d", got)
+	}
+}
+
+func TestNumaZoneFitScorePrefersTighterFit(t *testing.T) {
+	hint := twoZoneHint()
+	// Both zones have 4000m, so 3000m fits either of them with 1000m left
+	// over; assert its score lands in range, then that a 1000m request, which
+	// leaves 3000m of the zone idle, scores lower.
+	got := numaZoneFitScore(hint, 3000, 1<<30, 0)
+	if got <= 0 || got > maxNumaZoneFitScore {
+		t.Fatalf("expected a score in (0, %d], got %d", maxNumaZoneFitScore, got)
+	}
+
+	looser := numaZoneFitScore(hint, 1000, 1<<30, 0)
+	if looser >= got {
+		t.Fatalf("expected a smaller request to score lower (looser fit): got %d for loose vs %d for tight", looser, got)
+	}
+}
+
+func TestNumaZoneFitScoreRespectsGpuCapacity(t *testing.T) {
+	hint := twoZoneHint()
+	if got := numaZoneFitScore(hint, 1000, 1<<30, 2); got != 0 {
+		t.Fatalf("expected 0 when no single zone has 2 GPUs, got %d", got)
+	}
+	if got := numaZoneFitScore(hint, 1000, 1<<30, 1); got == 0 {
+		t.Fatalf("expected a nonzero score when a zone has 1 GPU and the pod asks for 1")
+	}
+}
+
+func TestNumaZoneFitScoreNilHint(t *testing.T) {
+	if got := numaZoneFitScore(nil, 1000, 1<<30, 0); got != 0 {
+		t.Fatalf("expected 0 for a nil hint, got %d", got)
+	}
+}